@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configFlagName = "config"
+	// envPrefix starts the environment variables of the settings, CHOPCHOP_MAX_SEVERITY setting --max-severity
+	envPrefix = "CHOPCHOP_"
+)
+
+// Sources of the settings, by order of precedence
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceFile    = "config file"
+	sourceDefault = "default"
+)
+
+// settingSources tells where the value of each flag of the running command comes from
+var settingSources = map[string]string{}
+
+func init() {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage the configuration of scans",
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "print the effective configuration, merging the config file, environment variables and flags",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigShow,
+	}
+	addSignaturesFlag(showCmd)
+	addFilterFlags(showCmd)
+	addScanFlags(showCmd)
+
+	configCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	return printConfig(cmd.Flags(), os.Stdout)
+}
+
+// envName returns the environment variable setting a flag
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// applyConfig sets the flags of the command that weren't given on the command line, from their environment
+// variable first and then from the config file. Flags take precedence over environment variables,
+// which take precedence over the config file, which takes precedence over the default values.
+func applyConfig(cmd *cobra.Command) error {
+	settings, err := readConfigFile(cmd)
+	if err != nil {
+		return err
+	}
+	settingSources = map[string]string{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Name == configFlagName || f.Name == "help" {
+			return
+		}
+		if f.Changed {
+			settingSources[f.Name] = sourceFlag
+			return
+		}
+		settingSources[f.Name] = sourceDefault
+		if value, ok := os.LookupEnv(envName(f.Name)); ok {
+			if err = f.Value.Set(value); err != nil {
+				err = fmt.Errorf("Invalid value for %s : %s. %v", envName(f.Name), value, err)
+				return
+			}
+			f.Changed = true
+			settingSources[f.Name] = sourceEnv
+			return
+		}
+		if value, ok := settings[f.Name]; ok {
+			if err = setFromConfig(f, value); err != nil {
+				err = fmt.Errorf("Invalid value for %s in config file : %v. %v", f.Name, value, err)
+				return
+			}
+			f.Changed = true
+			settingSources[f.Name] = sourceFile
+		}
+	})
+	return err
+}
+
+// readConfigFile reads the config file given by the config flag or its environment variable, if any.
+// Every setting of the file has to be the name of a flag of one of the commands.
+func readConfigFile(cmd *cobra.Command) (map[string]interface{}, error) {
+	path, err := cmd.Flags().GetString(configFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for config: %v", err)
+	}
+	if !cmd.Flags().Changed(configFlagName) {
+		path = os.Getenv(envName(configFlagName))
+	}
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read config file : %v", err)
+	}
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("Invalid config file %s : %v", path, err)
+	}
+	known := flagNames(rootCmd)
+	for name := range settings {
+		if !known[name] || name == configFlagName {
+			return nil, fmt.Errorf("Unknown setting in config file : %s. Settings are named after the flags, like threads or max-severity", name)
+		}
+	}
+	return settings, nil
+}
+
+// flagNames returns the names of the flags of the command and of its subcommands
+func flagNames(cmd *cobra.Command) map[string]bool {
+	names := map[string]bool{}
+	visit := func(f *pflag.Flag) { names[f.Name] = true }
+	cmd.Flags().VisitAll(visit)
+	cmd.PersistentFlags().VisitAll(visit)
+	for _, sub := range cmd.Commands() {
+		for name := range flagNames(sub) {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// setFromConfig sets a flag from the YAML value of the config file, each element of a list
+// or entry of a mapping being set in turn
+func setFromConfig(f *pflag.Flag, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		if !strings.HasSuffix(f.Value.Type(), "Slice") && !strings.HasSuffix(f.Value.Type(), "Array") {
+			return fmt.Errorf("A single value is expected")
+		}
+		for _, item := range v {
+			if err := f.Value.Set(fmt.Sprint(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[interface{}]interface{}:
+		if f.Value.Type() != "stringToString" {
+			return fmt.Errorf("A single value is expected")
+		}
+		for key, item := range v {
+			if err := f.Value.Set(fmt.Sprintf("%v=%v", key, item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		return nil
+	default:
+		return f.Value.Set(fmt.Sprint(v))
+	}
+}
+
+// printConfig writes the effective settings as a config file, commenting where the values come from
+func printConfig(flags *pflag.FlagSet, out io.Writer) error {
+	var names []string
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Name != configFlagName && f.Name != "help" {
+			names = append(names, f.Name)
+		}
+	})
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Effective configuration: flags override environment variables, which override the config file\n")
+	for _, name := range names {
+		data, err := yaml.Marshal(yaml.MapSlice{{Key: name, Value: flagValue(flags, name)}})
+		if err != nil {
+			return err
+		}
+		lines := strings.SplitN(string(data), "\n", 2)
+		source := settingSources[name]
+		switch source {
+		case "", sourceDefault:
+			buf.WriteString(lines[0])
+		case sourceEnv:
+			fmt.Fprintf(&buf, "%s # %s %s", lines[0], source, envName(name))
+		default:
+			fmt.Fprintf(&buf, "%s # %s", lines[0], source)
+		}
+		buf.WriteString("\n" + lines[1])
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// flagValue returns the value of a flag with its type, so that lists and mappings are printed as such
+func flagValue(flags *pflag.FlagSet, name string) interface{} {
+	var value interface{}
+	var err error
+	switch flags.Lookup(name).Value.Type() {
+	case "bool":
+		value, err = flags.GetBool(name)
+	case "int":
+		value, err = flags.GetInt(name)
+	case "int64":
+		value, err = flags.GetInt64(name)
+	case "stringSlice":
+		value, err = flags.GetStringSlice(name)
+	case "stringArray":
+		value, err = flags.GetStringArray(name)
+	case "stringToString":
+		value, err = flags.GetStringToString(name)
+	default:
+		value = flags.Lookup(name).Value.String()
+	}
+	if err != nil {
+		return flags.Lookup(name).Value.String()
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+
+	"github.com/spf13/cobra"
+)
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("min-severity", "", "", "Only keep checks with a severity over or equal to the specified one")                                    // --min-severity
+	cmd.Flags().StringSliceP("severity-filter", "", []string{}, "Filter by severity (engine will check for same severity checks)")                        // --severity-filter
+	cmd.Flags().StringSliceP("id-filters", "", []string{}, "Filter by the exact id of the check")                                                         // --id-filters
+	cmd.Flags().StringSliceP("exclude-id-filters", "", []string{}, "Exclude by the exact id of the check")                                                // --exclude-id-filters
+	cmd.Flags().StringSliceP("tag-filters", "", []string{}, "Filter by tag (engine will only check for checks having one of these tags)")                 // --tag-filters
+	cmd.Flags().StringSliceP("exclude-tag-filters", "", []string{}, "Exclude by tag (engine will skip checks having one of these tags)")                  // --exclude-tag-filters
+	cmd.Flags().StringSliceP("plugin-filters", "", []string{}, "Filter by the name of the plugin (engine will only check for plugin with the same name)") // --plugin-filters
+	cmd.Flags().StringSliceP("exclude-plugin-filters", "", []string{}, "Exclude by the name of the plugin (engine will skip plugins with the same name)") // --exclude-plugin-filters
+}
+
+func parseFilter(cmd *cobra.Command) (*core.Filter, error) {
+	minSeverity, err := cmd.Flags().GetString("min-severity")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for min-severity: %v", err)
+	}
+	if minSeverity != "" && !core.ValidSeverity(minSeverity) {
+		return nil, fmt.Errorf("Invalid min severity level : %s. Please use : %s", minSeverity, core.SeveritiesAsString())
+	}
+
+	severities, err := cmd.Flags().GetStringSlice("severity-filter")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for severity-filter: %v", err)
+	}
+	for _, severity := range severities {
+		if !core.ValidSeverity(severity) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, core.SeveritiesAsString())
+		}
+	}
+
+	filter := &core.Filter{
+		MinSeverity: minSeverity,
+		Severities:  severities,
+	}
+
+	lists := []struct {
+		flag  string
+		value *[]string
+	}{
+		{"id-filters", &filter.IDs},
+		{"exclude-id-filters", &filter.ExcludeIDs},
+		{"tag-filters", &filter.Tags},
+		{"exclude-tag-filters", &filter.ExcludeTags},
+		{"plugin-filters", &filter.Names},
+		{"exclude-plugin-filters", &filter.ExcludeNames},
+	}
+	for _, list := range lists {
+		values, err := cmd.Flags().GetStringSlice(list.flag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %v", list.flag, err)
+		}
+		*list.value = values
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/nuclei"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	signaturesCmd := &cobra.Command{
+		Use:   "signatures",
+		Short: "manage signature files",
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import <file or directory>...",
+		Short: "convert signatures from other tools into chopchop signatures",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runImport,
+	}
+	importCmd.Flags().StringP("from", "", "nuclei", "format of the imported signatures (nuclei)")              // --from
+	importCmd.Flags().StringP("output-file", "", "", "path of the generated signature file (default: stdout)") // --output-file
+
+	signaturesCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(signaturesCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	from, err := cmd.Flags().GetString("from")
+	if err != nil {
+		return fmt.Errorf("invalid value for from: %v", err)
+	}
+	if from != "nuclei" {
+		return fmt.Errorf("Invalid import format : %s. Please use : nuclei", from)
+	}
+	outputFile, err := cmd.Flags().GetString("output-file")
+	if err != nil {
+		return fmt.Errorf("invalid value for output-file: %v", err)
+	}
+
+	files, err := templateFiles(args)
+	if err != nil {
+		return err
+	}
+
+	signatures := core.NewSignatures()
+	skipped := table.NewWriter()
+	skipped.SetOutputMirror(os.Stderr)
+	skipped.AppendHeader(table.Row{"Template", "Reason"})
+	converted := 0
+	for _, file := range files {
+		plugin, err := importNucleiTemplate(file)
+		if err == nil {
+			// a template clashing with the ones already converted, on its id for instance, is skipped too
+			candidate := &core.Signatures{Plugins: append(append([]*core.Plugin{}, signatures.Plugins...), plugin)}
+			err = candidate.Validate()
+		}
+		if err != nil {
+			skipped.AppendRow([]interface{}{file, err})
+			continue
+		}
+		signatures.AddPlugin(plugin)
+		converted++
+	}
+
+	if converted < len(files) {
+		skipped.Render()
+	}
+	fmt.Fprintf(os.Stderr, "Converted %d of %d templates\n", converted, len(files))
+
+	// the generated file must load like any signature file
+	if err := signatures.Validate(); err != nil {
+		return fmt.Errorf("Invalid generated signatures : %v", err)
+	}
+
+	data, err := yaml.Marshal(signatures)
+	if err != nil {
+		return err
+	}
+	if outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(outputFile, data, 0644)
+}
+
+func importNucleiTemplate(file string) (*core.Plugin, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	template, err := nuclei.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return nuclei.Convert(template)
+}
+
+// templateFiles lists the YAML files given as arguments, directories being walked recursively
+func templateFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
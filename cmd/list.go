@@ -3,16 +3,13 @@ package cmd
 import (
 	"fmt"
 	"gochopchop/core"
+	"gochopchop/internal/formatting"
 	"os"
+	"strings"
 
-	"github.com/jedib0t/go-pretty/table"
 	"github.com/spf13/cobra"
 )
 
-type listOptions struct {
-	Severity string
-}
-
 func init() {
 	pluginCmd := &cobra.Command{
 		Use:   "plugins",
@@ -20,8 +17,20 @@ func init() {
 		RunE:  runList,
 	}
 	addSignaturesFlag(pluginCmd)
-	pluginCmd.Flags().StringP("severity", "s", "", "severity option for list tag") // --severity ou -s
+	addFilterFlags(pluginCmd)
+	pluginCmd.Flags().StringP("severity", "s", "", "severity option for list tag")                                           // --severity ou -s
+	pluginCmd.Flags().StringP("output", "o", "table", "output format ("+strings.Join(formatting.SignatureFormats, ", ")+")") // --output ou -o
+
+	showCmd := &cobra.Command{
+		Use:   "show <id or name>",
+		Short: "show the details of a check",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runShow,
+	}
+	addSignaturesFlag(showCmd)
+	showCmd.Flags().StringP("output", "o", "table", "output format ("+strings.Join(formatting.SignatureFormats, ", ")+")") // --output ou -o
 
+	pluginCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(pluginCmd)
 }
 
@@ -30,29 +39,60 @@ func runList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	options, err := parseOptions(cmd)
+	filter, err := parseListFilter(cmd)
+	if err != nil {
+		return err
+	}
+	signatures.Filter(filter)
+	output, err := parseOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	return formatting.PrintCheckEntries(formatting.CheckEntries(signatures), output, os.Stdout)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	signatures, err := parseSignatures(cmd)
 	if err != nil {
 		return err
 	}
-	cpt := 0
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"URL", "Plugin Name", "Severity", "Description"})
-	for _, plugin := range signatures.Plugins {
-		for _, check := range plugin.Checks {
-			if options.Severity == "" || options.Severity == string(check.Severity) {
-				t.AppendRow([]interface{}{plugin.Endpoint, check.Name, check.Severity, check.Description})
-				cpt++
-			}
+	output, err := parseOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	var entries []formatting.CheckEntry
+	for _, entry := range formatting.CheckEntries(signatures) {
+		if entry.ID == args[0] || strings.EqualFold(entry.Name, args[0]) {
+			entries = append(entries, entry)
 		}
 	}
-	t.AppendFooter(table.Row{"", "", "Total Checks", cpt})
-	t.Render()
-	return nil
+	if len(entries) == 0 {
+		return fmt.Errorf("No check found with id or name %s", args[0])
+	}
+	if output == "table" {
+		formatting.PrintCheckDetails(entries, os.Stdout)
+		return nil
+	}
+	return formatting.PrintCheckEntries(entries, output, os.Stdout)
+}
+
+func parseOutputFormat(cmd *cobra.Command) (string, error) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", fmt.Errorf("invalid value for output: %v", err)
+	}
+	if !formatting.ValidSignatureFormat(output) {
+		return "", fmt.Errorf("Invalid output format : %s. Please use : %s", output, strings.Join(formatting.SignatureFormats, ", "))
+	}
+	return output, nil
 }
 
-func parseOptions(cmd *cobra.Command) (*listOptions, error) {
-	options := new(listOptions)
+// parseListFilter reads the common filter flags, --severity being kept as a shorthand for --severity-filter
+func parseListFilter(cmd *cobra.Command) (*core.Filter, error) {
+	filter, err := parseFilter(cmd)
+	if err != nil {
+		return nil, err
+	}
 	severity, err := cmd.Flags().GetString("severity")
 	if err != nil {
 		return nil, fmt.Errorf("invalid value for severity: %v", err)
@@ -61,7 +101,7 @@ func parseOptions(cmd *cobra.Command) (*listOptions, error) {
 		if !core.ValidSeverity(severity) {
 			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, core.SeveritiesAsString())
 		}
-		options.Severity = severity
+		filter.Severities = append(filter.Severities, severity)
 	}
-	return options, nil
+	return filter, nil
 }
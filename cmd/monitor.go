@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/monitor"
+	"gochopchop/internal/schedule"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// webhookTimeout is the time given to a webhook to accept the changes
+const webhookTimeout = 30 * time.Second
+
+func init() {
+	monitorCmd := &cobra.Command{
+		Use:   "monitor [url]",
+		Short: "scan urls on a schedule and report the findings new or resolved since the previous scan",
+		RunE:  runMonitor,
+	}
+	addSignaturesFlag(monitorCmd)
+	addFilterFlags(monitorCmd)
+	addScannerFlags(monitorCmd)
+
+	monitorCmd.Flags().StringP("url-file", "u", "", "path to a specified file containing urls to test")                // --url-file ou -u
+	monitorCmd.Flags().StringP("schedule", "", "@daily", "cron expression of the scans, or @every and a duration")     // --schedule
+	monitorCmd.Flags().StringP("history-dir", "", "chopchop-history", "directory the findings of every scan are kept") // --history-dir
+	monitorCmd.Flags().IntP("keep", "", 30, "number of scans kept in the history, 0 to keep them all")                 // --keep
+	monitorCmd.Flags().StringSliceP("export", "e", []string{}, "export of the changes (csv and json)")                 // --export ou -e
+	monitorCmd.Flags().StringP("export-filename", "", "gochopchop_changes", "filename prefix for export files")        // --export-filename
+	monitorCmd.Flags().StringP("webhook", "", "", "URL the changes are posted to as JSON")                             // --webhook
+	monitorCmd.Flags().StringP("suppressions", "", "", "YAML file of the findings accepted as risks")                  // --suppressions
+	rootCmd.AddCommand(monitorCmd)
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	signatures, err := parseSignatures(cmd)
+	if err != nil {
+		return err
+	}
+	filter, err := parseFilter(cmd)
+	if err != nil {
+		return err
+	}
+	signatures.Filter(filter)
+	if len(signatures.Plugins) == 0 {
+		return fmt.Errorf("No check left to run after applying the filters")
+	}
+
+	urlFile, err := cmd.Flags().GetString("url-file")
+	if err != nil {
+		return fmt.Errorf("invalid value for url-file: %v", err)
+	}
+	urls, err := parseUrls(urlFile, args)
+	if err != nil {
+		return err
+	}
+
+	config := &core.Config{Filter: filter}
+	if err := parseScannerConfig(cmd, config); err != nil {
+		return err
+	}
+	scanner, err := newScanner(config, signatures)
+	if err != nil {
+		return err
+	}
+
+	spec, err := cmd.Flags().GetString("schedule")
+	if err != nil {
+		return fmt.Errorf("invalid value for schedule: %v", err)
+	}
+	s, err := schedule.Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	historyDir, err := cmd.Flags().GetString("history-dir")
+	if err != nil {
+		return fmt.Errorf("invalid value for history-dir: %v", err)
+	}
+	keep, err := cmd.Flags().GetInt("keep")
+	if err != nil {
+		return fmt.Errorf("invalid value for keep: %v", err)
+	}
+	if keep < 0 {
+		return fmt.Errorf("The number of scans kept can't be negative")
+	}
+	history, err := monitor.NewHistory(historyDir, keep)
+	if err != nil {
+		return fmt.Errorf("Could not create the history directory : %v", err)
+	}
+
+	suppressions, err := parseSuppressions(cmd)
+	if err != nil {
+		return err
+	}
+
+	emitters, err := parseEmitters(cmd)
+	if err != nil {
+		return err
+	}
+
+	m := &monitor.Monitor{
+		Scanner:      scanner,
+		Urls:         urls,
+		History:      history,
+		Suppressions: suppressions,
+		Emitters:     emitters,
+		MaxDuration:  config.MaxDuration,
+	}
+	return m.Run(cmd.Context(), s)
+}
+
+// parseEmitters returns the outputs of the changes: the table printed, the exports and the webhook
+func parseEmitters(cmd *cobra.Command) ([]monitor.IEmitter, error) {
+	emitters := []monitor.IEmitter{monitor.TableEmitter{Writer: os.Stdout}}
+
+	exportFormats, err := cmd.Flags().GetStringSlice("export")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for export formats: %v", err)
+	}
+	for _, f := range exportFormats {
+		if f != "csv" && f != "json" {
+			return nil, fmt.Errorf("invalid value for export: %v , expected csv or json", f)
+		}
+	}
+	exportFilename, err := cmd.Flags().GetString("export-filename")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for exportFilename: %v", err)
+	}
+	if len(exportFormats) > 0 {
+		emitters = append(emitters, monitor.ExportEmitter{Formats: exportFormats, Filename: exportFilename})
+	}
+
+	webhook, err := cmd.Flags().GetString("webhook")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for webhook: %v", err)
+	}
+	if webhook != "" {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Invalid webhook : %s. Please use an http or https URL", webhook)
+		}
+		emitters = append(emitters, monitor.WebhookEmitter{URL: webhook, Client: &http.Client{Timeout: webhookTimeout}})
+	}
+	return emitters, nil
+}
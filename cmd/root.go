@@ -36,6 +36,9 @@ var v string
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
 		if err := setupLogs(os.Stdout, v); err != nil {
 			return err
 		}
@@ -44,6 +47,7 @@ func Execute() {
 
 	rootCmd.PersistentFlags().StringVarP(&v, "verbosity", "v", log.WarnLevel.String(), "Log level (debug, info, warn, error, fatal, panic)")
 	rootCmd.PersistentFlags().IntP("threads", "", 1, "Number of threads")
+	rootCmd.PersistentFlags().StringP(configFlagName, "", "", "path to a YAML config file, also read from "+envName(configFlagName))
 	ctx, cancel := context.WithCancel(context.Background())
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -54,11 +58,14 @@ func Execute() {
 	go func() {
 		select {
 		case <-sigs:
-			log.Warn("\n[!] Keyboard interrupt detected.")
+			// the running command stops and saves its progress, a second interrupt exits right away
+			log.Warn("\n[!] Keyboard interrupt detected, stopping. Interrupt again to exit immediately.")
 			cancel()
-			os.Exit(1)
 		case <-ctx.Done():
+			return
 		}
+		<-sigs
+		os.Exit(1)
 	}()
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		log.Warn(err)
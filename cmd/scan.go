@@ -2,17 +2,22 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"gochopchop/core"
 	"gochopchop/internal/export"
 	"gochopchop/internal/formatting"
-	"gochopchop/internal/httpget"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
 func init() {
@@ -22,18 +27,27 @@ func init() {
 		RunE:  runScan,
 	}
 	addSignaturesFlag(scanCmd)
-
-	scanCmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                                                                                    // --insecure ou -n
-	scanCmd.Flags().StringP("url-file", "u", "", "path to a specified file containing urls to test")                                                          // --uri-file ou -f
-	scanCmd.Flags().StringP("max-severity", "b", "", "block the CI pipeline if severity is over or equal specified flag")                                     // --max-severity ou -m
-	scanCmd.Flags().StringSliceP("export", "e", []string{}, "export of the output (csv and json)")                                                            //--export ou --e
-	scanCmd.Flags().StringP("export-filename", "", "", "filename for export files")                                                                           // --export-filename
-	scanCmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)")                                                                  // --timeout ou -ts
-	scanCmd.Flags().StringP("severity-filter", "", "", "Filter by severity (engine will check for same severity checks)")                                     // --severity-filter
-	scanCmd.Flags().StringSliceP("plugin-filters", "", []string{}, "Filter by the name of the plugin (engine will only check for plugin with the same name)") // --plugin-filter
+	addFilterFlags(scanCmd)
+	addScanFlags(scanCmd)
 	rootCmd.AddCommand(scanCmd)
 }
 
+// addScanFlags adds the flags of the scan settings, shared by the scan and config show commands
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("url-file", "u", "", "path to a specified file containing urls to test")                      // --uri-file ou -f
+	cmd.Flags().StringP("max-severity", "b", "", "block the CI pipeline if severity is over or equal specified flag") // --max-severity ou -m
+	cmd.Flags().StringSliceP("export", "e", []string{}, "export of the output (csv and json)")                        //--export ou --e
+	cmd.Flags().StringP("export-filename", "", "", "filename for export files")                                       // --export-filename
+
+	cmd.Flags().StringP("baseline", "", "", "JSON export of a previous scan to compare the findings with") // --baseline
+	cmd.Flags().StringP("suppressions", "", "", "YAML file of the findings accepted as risks")             // --suppressions
+
+	cmd.Flags().StringP("state-file", "", "", "file the progress of the scan is saved to, to resume it") // --state-file
+	cmd.Flags().StringP("resume", "", "", "state file of an interrupted scan to resume")                 // --resume
+
+	addScannerFlags(cmd)
+}
+
 func runScan(cmd *cobra.Command, args []string) error {
 	config, err := parseConfig(cmd, args)
 	if err != nil {
@@ -44,45 +58,170 @@ func runScan(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	signatures.Filter(config.Filter)
+	if len(signatures.Plugins) == 0 {
+		return fmt.Errorf("No check left to run after applying the filters")
+	}
 
 	begin := time.Now()
 
-	fetcher := httpget.NewFetcher(config.HTTP.Insecure, config.HTTP.Timeout)
-	noRedirectFetcher := httpget.NewNoRedirectFetcher(config.HTTP.Insecure, config.HTTP.Timeout)
+	scanner, err := newScanner(config, signatures)
+	if err != nil {
+		return err
+	}
 
-	scanner := core.NewScanner(fetcher, noRedirectFetcher, signatures, config.Threads)
+	state := config.State
+	if state == nil {
+		state = core.NewScanState(config.Urls)
+	}
+	scanner.State = state
+	stopSaving := func() {}
+	if config.StateFile != "" {
+		stopSaving = saveStatePeriodically(config.StateFile, state)
+	}
 
-	result, err := scanner.Scan(cmd.Context(), config.Urls)
-	if err != nil {
+	ctx := cmd.Context()
+	if config.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.MaxDuration)
+		defer cancel()
+	}
+
+	_, err = scanner.Scan(ctx, config.Urls)
+	stopSaving()
+	interrupted := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+	var notRun *core.NotRunError
+	if errors.As(err, &notRun) {
+		formatting.PrintNotRun(notRun.NotRun, os.Stdout)
+	} else if err != nil && !interrupted {
 		return err
 	}
 
+	stateFile := config.StateFile
+	if (interrupted || notRun != nil) && stateFile == "" {
+		// the jobs not run are left to a resumed scan
+		stateFile = config.ExportFilename + ".state.json"
+	}
+	if stateFile != "" {
+		if err := saveState(stateFile, state); err != nil {
+			log.Error("Could not save the scan state : ", err)
+		}
+	}
+	if notRun != nil {
+		log.Warnf("%v. Run them with --resume %s", notRun, stateFile)
+	}
+	result := state.Outputs()
+
 	log.Info("Scan execution time:", time.Since(begin))
 
-	if len(result) > 0 {
+	if config.Baseline != "" {
+		baseline, err := export.ImportJSON(config.Baseline)
+		if err != nil {
+			return fmt.Errorf("Could not read baseline %s : %v", config.Baseline, err)
+		}
+		result = core.CompareBaseline(result, baseline)
+		if interrupted {
+			// the checks not run yet can't tell if the findings of the baseline are resolved
+			result = withoutStatus(result, core.StatusResolved)
+		} else if notRun != nil {
+			result = withoutResolvedNotRun(result, notRun.NotRun)
+		}
+	}
+
+	if config.Suppressions != nil {
+		result = config.Suppressions.Apply(result, time.Now())
+	}
 
-		formatting.PrintTable(result, os.Stdout)
+	exportFilename := config.ExportFilename
+	if interrupted {
+		// the exports of an incomplete scan never replace the ones of a complete scan
+		exportFilename += ".partial"
+		result = core.MarkIncomplete(result)
+	}
+	reportErr := report(result, config, exportFilename)
 
-		if contains(config.ExportFormats, "json") {
-			export.ExportJSON(config.ExportFilename, result)
-		}
-		if contains(config.ExportFormats, "csv") {
-			export.ExportCSV(config.ExportFilename, result)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("Scan stopped by the max duration of %s after %d jobs with %d findings, the results are incomplete. Resume it with --resume %s",
+			config.MaxDuration, state.Completed(), len(state.Outputs()), stateFile)
+	}
+	if interrupted {
+		return fmt.Errorf("Scan interrupted after %d jobs with %d findings, the results are incomplete. Resume it with --resume %s",
+			state.Completed(), len(state.Outputs()), stateFile)
+	}
+	return reportErr
+}
+
+// report prints and exports the findings, returning an error if one of them reaches the max severity
+func report(result []core.Output, config *core.Config, exportFilename string) error {
+	if len(result) == 0 {
+		log.Info("No vulnerabilities found. Exiting...")
+		return nil
+	}
+
+	// accepted risks are only listed in the exports
+	var visible []core.Output
+	for _, output := range result {
+		switch output.Suppression {
+		case core.SuppressionActive:
+			continue
+		case core.SuppressionExpired:
+			log.Warnf("The suppression of %s on %s has expired (%s)", output.ID, output.URL, output.Justification)
 		}
+		visible = append(visible, output)
+	}
+	if suppressed := len(result) - len(visible); suppressed > 0 {
+		log.Infof("%d suppressed findings not shown", suppressed)
+	}
+	if len(visible) > 0 {
+		formatting.PrintTable(visible, os.Stdout)
+	}
 
-		if config.MaxSeverity != "" {
-			for _, output := range result {
-				if core.SeverityReached(config.MaxSeverity, output.Severity) {
-					return fmt.Errorf("Max severity level reached, exiting with error code")
-				}
+	if contains(config.ExportFormats, "json") {
+		export.ExportJSON(exportFilename, result)
+	}
+	if contains(config.ExportFormats, "csv") {
+		export.ExportCSV(exportFilename, result)
+	}
+
+	if config.MaxSeverity != "" {
+		for _, output := range visible {
+			// with a baseline, only new findings block the pipeline
+			if config.Baseline != "" && output.Status != core.StatusNew {
+				continue
+			}
+			if core.SeverityReached(config.MaxSeverity, output.Severity) {
+				return fmt.Errorf("Max severity level reached, exiting with error code")
 			}
 		}
-	} else {
-		log.Info("No vulnerabilities found. Exiting...")
 	}
 	return nil
 }
 
+// withoutResolvedNotRun drops the resolved findings of the plugins that weren't run
+func withoutResolvedNotRun(outputs []core.Output, notRun []core.NotRun) []core.Output {
+	skipped := make(map[string]bool)
+	for _, job := range notRun {
+		skipped[job.URL] = true
+	}
+	var kept []core.Output
+	for _, output := range outputs {
+		if output.Status != core.StatusResolved || !skipped[output.URL] {
+			kept = append(kept, output)
+		}
+	}
+	return kept
+}
+
+func withoutStatus(outputs []core.Output, status string) []core.Output {
+	var kept []core.Output
+	for _, output := range outputs {
+		if output.Status != status {
+			kept = append(kept, output)
+		}
+	}
+	return kept
+}
+
 func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 
 	urlFile, err := cmd.Flags().GetString("url-file")
@@ -90,6 +229,88 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 		return nil, fmt.Errorf("invalid value for url-file: %v", err)
 	}
 
+	stateFile, state, err := parseState(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && (urlFile != "" || len(args) >= 1) {
+		// the urls of a resumed scan are the ones of its state
+		return nil, fmt.Errorf("Can't specify url when resuming a scan")
+	}
+	var urls []string
+	if state != nil {
+		urls = state.Urls()
+	} else if urls, err = parseUrls(urlFile, args); err != nil {
+		return nil, err
+	}
+
+	filter, err := parseFilter(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	exportFormats, err := cmd.Flags().GetStringSlice("export")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for export formats: %v", err)
+	}
+	if len(exportFormats) > 0 {
+		for _, f := range exportFormats {
+			if f != "csv" && f != "json" {
+				return nil, fmt.Errorf("invalid value for export: %v , expected csv or json", f)
+			}
+		}
+	}
+
+	maxSeverity, err := cmd.Flags().GetString("max-severity")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for max sevirity : %v", err)
+	}
+	if maxSeverity != "" && !core.ValidSeverity(maxSeverity) {
+		return nil, fmt.Errorf("Invalid max severity level : %s. Please use : %s", maxSeverity, core.SeveritiesAsString())
+	}
+
+	exportFilename, err := cmd.Flags().GetString("export-filename")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for exportFilename: %v", err)
+	}
+	if exportFilename == "" {
+		now := time.Now().Format("2006-01-02_15-04-05")
+		exportFilename = fmt.Sprintf("gochopchop_%s", now)
+	}
+
+	baseline, err := cmd.Flags().GetString("baseline")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for baseline: %v", err)
+	}
+	if _, err := os.Stat(baseline); baseline != "" && err != nil {
+		return nil, fmt.Errorf("Path of baseline file is not valid")
+	}
+
+	suppressions, err := parseSuppressions(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &core.Config{
+		MaxSeverity:    maxSeverity,
+		ExportFormats:  exportFormats,
+		Urls:           urls,
+		ExportFilename: exportFilename,
+		Filter:         filter,
+		Baseline:       baseline,
+		Suppressions:   suppressions,
+		StateFile:      stateFile,
+		State:          state,
+	}
+	if err := parseScannerConfig(cmd, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// parseUrls returns the url given as argument or the urls of the url file
+func parseUrls(urlFile string, args []string) ([]string, error) {
 	if urlFile != "" && len(args) >= 1 {
 		// both urlFile and url are set, abort
 		return nil, fmt.Errorf("Can't specify url with url list flag")
@@ -132,85 +353,61 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 			return nil, fmt.Errorf("Please provide a valid URL")
 		}
 	}
+	return urls, nil
+}
 
-	insecure, err := cmd.Flags().GetBool("insecure")
+func parseProxy(cmd *cobra.Command) (*url.URL, error) {
+	proxy, err := cmd.Flags().GetString("proxy")
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for insecure: %v", err)
+		return nil, fmt.Errorf("invalid value for proxy: %v", err)
 	}
-
-	severityFilter, err := cmd.Flags().GetString("severity-filter")
-	if err != nil {
-		return nil, fmt.Errorf("invalid value for severity-filter: %v", err)
+	if proxy == "" {
+		return nil, nil
 	}
-	if severityFilter != "" {
-		if !core.ValidSeverity(severityFilter) {
-			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severityFilter, core.SeveritiesAsString())
-		}
-	}
-
-	pluginFilters, err := cmd.Flags().GetStringSlice("plugin-filters")
-	if err != nil {
-		return nil, fmt.Errorf("invalid value for plugin-filters: %v", err)
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+		return nil, fmt.Errorf("Invalid proxy : %s. Please use : http://host:port, https://host:port or socks5://host:port", proxy)
 	}
+	return u, nil
+}
 
-	exportFormats, err := cmd.Flags().GetStringSlice("export")
+func parseHeaders(cmd *cobra.Command) (http.Header, error) {
+	values, err := cmd.Flags().GetStringArray("header")
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for export formats: %v", err)
+		return nil, fmt.Errorf("invalid value for header: %v", err)
 	}
-	if len(exportFormats) > 0 {
-		for _, f := range exportFormats {
-			if f != "csv" && f != "json" {
-				return nil, fmt.Errorf("invalid value for export: %v , expected csv or json", f)
-			}
+	headers := make(http.Header)
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return nil, fmt.Errorf("Invalid header : %s. Please use : Name: value", value)
 		}
+		headers.Add(name, strings.TrimSpace(parts[1]))
 	}
+	return headers, nil
+}
 
-	maxSeverity, err := cmd.Flags().GetString("max-severity")
+func parseSuppressions(cmd *cobra.Command) (*core.Suppressions, error) {
+	suppressionsFile, err := cmd.Flags().GetString("suppressions")
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for max sevirity : %v", err)
+		return nil, fmt.Errorf("invalid value for suppressions: %v", err)
 	}
-	if maxSeverity != "" && !core.ValidSeverity(maxSeverity) {
-		return nil, fmt.Errorf("Invalid max severity level : %s. Please use : %s", maxSeverity, core.SeveritiesAsString())
+	if suppressionsFile == "" {
+		return nil, nil
 	}
-
-	exportFilename, err := cmd.Flags().GetString("export-filename")
+	data, err := ioutil.ReadFile(suppressionsFile)
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for exportFilename: %v", err)
+		return nil, fmt.Errorf("Could not read suppressions file : %v", err)
 	}
-	if exportFilename == "" {
-		now := time.Now().Format("2006-01-02_15-04-05")
-		exportFilename = fmt.Sprintf("gochopchop_%s", now)
-	}
-
-	timeout, err := cmd.Flags().GetInt("timeout")
-	if err != nil {
-		return nil, fmt.Errorf("Invalid value for timeout: %v", err)
-	}
-
-	threads, err := rootCmd.Flags().GetInt("threads")
-	if err != nil {
-		return nil, fmt.Errorf("invalid value for threads: %w", err)
-	}
-
-	if threads <= 0 {
-		return nil, fmt.Errorf("The number of threads must be positive")
+	suppressions := &core.Suppressions{}
+	if err := yaml.UnmarshalStrict(data, suppressions); err != nil {
+		return nil, fmt.Errorf("Invalid suppressions file %s : %v", suppressionsFile, err)
 	}
-
-	config := &core.Config{
-		HTTP: core.HTTPConfig{
-			Insecure: insecure,
-			Timeout:  timeout,
-		},
-		MaxSeverity:    maxSeverity,
-		ExportFormats:  exportFormats,
-		Urls:           urls,
-		ExportFilename: exportFilename,
-		SeverityFilter: severityFilter,
-		PluginFilter:   pluginFilters,
-		Threads:        threads,
+	if err := suppressions.Validate(); err != nil {
+		return nil, err
 	}
-
-	return config, nil
+	return suppressions, nil
 }
 
 func isURL(str string) bool {
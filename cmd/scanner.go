@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/httpget"
+
+	"github.com/spf13/cobra"
+)
+
+// addScannerFlags adds the flags of the settings of the scanner itself, shared by the scan and serve commands
+func addScannerFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                   // --insecure ou -n
+	cmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)") // --timeout ou -ts
+
+	cmd.Flags().Int64P("max-body-size", "", 10*1024*1024, "maximum number of bytes read from a response body")                // --max-body-size
+	cmd.Flags().BoolP("header-audit", "", true, "report missing or weak security headers, --header-audit=false disabling it") // --header-audit
+	cmd.Flags().StringToStringP("header-audit-severity", "", nil, "severity of header audit rules (rule-id=Severity)")        // --header-audit-severity
+	cmd.Flags().StringP("soft-404", "", core.Soft404Off, "findings on catch-all pages: off, suppress, downgrade")             // --soft-404
+
+	cmd.Flags().StringP("proxy", "", "", "proxy URL the requests go through (http, https or socks5)")  // --proxy
+	cmd.Flags().StringArrayP("header", "H", []string{}, "header added to every request (Name: value)") // --header ou -H
+
+	cmd.Flags().DurationP("max-duration", "", 0, "maximum duration of the scan, like 30m (default: no limit)")       // --max-duration
+	cmd.Flags().DurationP("target-budget", "", 0, "maximum duration of the scan of each target (default: no limit)") // --target-budget
+}
+
+// parseScannerConfig sets the settings of the scanner of the config from the flags added by addScannerFlags
+func parseScannerConfig(cmd *cobra.Command, config *core.Config) error {
+	insecure, err := cmd.Flags().GetBool("insecure")
+	if err != nil {
+		return fmt.Errorf("invalid value for insecure: %v", err)
+	}
+
+	timeout, err := cmd.Flags().GetInt("timeout")
+	if err != nil {
+		return fmt.Errorf("Invalid value for timeout: %v", err)
+	}
+
+	maxBodySize, err := cmd.Flags().GetInt64("max-body-size")
+	if err != nil {
+		return fmt.Errorf("invalid value for max-body-size: %v", err)
+	}
+	if maxBodySize < 0 {
+		return fmt.Errorf("The maximum body size must be positive, 0 disabling the limit")
+	}
+
+	headerAudit, err := cmd.Flags().GetBool("header-audit")
+	if err != nil {
+		return fmt.Errorf("invalid value for header-audit: %v", err)
+	}
+
+	headerAuditSeverities, err := cmd.Flags().GetStringToString("header-audit-severity")
+	if err != nil {
+		return fmt.Errorf("invalid value for header-audit-severity: %v", err)
+	}
+
+	soft404, err := cmd.Flags().GetString("soft-404")
+	if err != nil {
+		return fmt.Errorf("invalid value for soft-404: %v", err)
+	}
+	if !core.ValidSoft404Mode(soft404) {
+		return fmt.Errorf("Invalid soft-404 mode : %s. Please use : %s", soft404, core.Soft404ModesAsString())
+	}
+
+	proxy, err := parseProxy(cmd)
+	if err != nil {
+		return err
+	}
+
+	headers, err := parseHeaders(cmd)
+	if err != nil {
+		return err
+	}
+
+	maxDuration, err := cmd.Flags().GetDuration("max-duration")
+	if err != nil {
+		return fmt.Errorf("invalid value for max-duration: %v", err)
+	}
+	targetBudget, err := cmd.Flags().GetDuration("target-budget")
+	if err != nil {
+		return fmt.Errorf("invalid value for target-budget: %v", err)
+	}
+	if maxDuration < 0 || targetBudget < 0 {
+		return fmt.Errorf("The max duration and target budget must be positive, 0 disabling the limit")
+	}
+
+	threads, err := rootCmd.Flags().GetInt("threads")
+	if err != nil {
+		return fmt.Errorf("invalid value for threads: %w", err)
+	}
+
+	if threads <= 0 {
+		return fmt.Errorf("The number of threads must be positive")
+	}
+
+	config.HTTP = core.HTTPConfig{
+		Insecure:    insecure,
+		Timeout:     timeout,
+		MaxBodySize: maxBodySize,
+		Proxy:       proxy,
+		Headers:     headers,
+	}
+	config.Threads = threads
+	config.HeaderAudit = headerAudit
+	config.HeaderAuditSeverities = headerAuditSeverities
+	config.Soft404 = soft404
+	config.MaxDuration = maxDuration
+	config.TargetBudget = targetBudget
+	return nil
+}
+
+// newScanner returns a scanner of the signatures set up by the config
+func newScanner(config *core.Config, signatures *core.Signatures) (*core.Scanner, error) {
+	fetcher := httpget.NewFetcher(config.HTTP.Insecure, config.HTTP.Timeout, config.HTTP.MaxBodySize, config.HTTP.Proxy, config.HTTP.Headers)
+	noRedirectFetcher := httpget.NewNoRedirectFetcher(config.HTTP.Insecure, config.HTTP.Timeout, config.HTTP.MaxBodySize, config.HTTP.Proxy, config.HTTP.Headers)
+
+	scanner := core.NewScanner(fetcher, noRedirectFetcher, signatures, config.Threads)
+	scanner.Soft404 = config.Soft404
+	scanner.TargetBudget = config.TargetBudget
+	scanner.HeaderAudit = nil
+	if config.HeaderAudit {
+		audit, err := core.NewHeaderAudit(config.HeaderAuditSeverities)
+		if err != nil {
+			return nil, err
+		}
+		if config.Filter != nil {
+			audit.Filter(config.Filter)
+		}
+		scanner.HeaderAudit = audit
+	}
+	return scanner, nil
+}
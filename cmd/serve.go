@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/server"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout is the time given to the requests in progress once the server is stopped
+const shutdownTimeout = 10 * time.Second
+
+func init() {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "run an HTTP API to submit scans and fetch their results",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+	addSignaturesFlag(serveCmd)
+	addScannerFlags(serveCmd)
+
+	serveCmd.Flags().StringP("listen", "", "127.0.0.1:8000", "address the API listens on")                      // --listen
+	serveCmd.Flags().IntP("workers", "", 2, "number of scans run at the same time")                             // --workers
+	serveCmd.Flags().IntP("queue-size", "", 100, "number of scans waiting to be run at most")                   // --queue-size
+	serveCmd.Flags().IntP("history", "", 100, "number of finished scans kept")                                  // --history
+	serveCmd.Flags().StringP("api-token", "", "", "bearer token required by every request (default: no token)") // --api-token
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	signatures, err := parseSignatures(cmd)
+	if err != nil {
+		return err
+	}
+	config := &core.Config{}
+	if err := parseScannerConfig(cmd, config); err != nil {
+		return err
+	}
+	scanner, err := newScanner(config, signatures)
+	if err != nil {
+		return err
+	}
+
+	listen, err := cmd.Flags().GetString("listen")
+	if err != nil {
+		return fmt.Errorf("invalid value for listen: %v", err)
+	}
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		return fmt.Errorf("invalid value for workers: %v", err)
+	}
+	queueSize, err := cmd.Flags().GetInt("queue-size")
+	if err != nil {
+		return fmt.Errorf("invalid value for queue-size: %v", err)
+	}
+	history, err := cmd.Flags().GetInt("history")
+	if err != nil {
+		return fmt.Errorf("invalid value for history: %v", err)
+	}
+	if workers <= 0 || queueSize < 0 || history < 0 {
+		return fmt.Errorf("The number of workers must be positive, the queue size and history can't be negative")
+	}
+	token, err := cmd.Flags().GetString("api-token")
+	if err != nil {
+		return fmt.Errorf("invalid value for api-token: %v", err)
+	}
+
+	api := server.New(scanner, queueSize)
+	api.MaxDuration = config.MaxDuration
+	api.Token = token
+	api.History = history
+
+	ctx := cmd.Context()
+	api.Start(ctx, workers)
+	httpServer := &http.Server{Addr: listen, Handler: api}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+	log.Infof("Listening on %s with %d plugins", listen, len(signatures.Plugins))
+	if token == "" {
+		log.Warn("No API token set, anyone reaching ", listen, " can run scans")
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
@@ -5,10 +5,8 @@ import (
 	"gochopchop/core"
 	"io/ioutil"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 var signatureFlagName = "signatures"
@@ -41,49 +39,5 @@ func parseSignatures(cmd *cobra.Command) (*core.Signatures, error) {
 		return nil, err
 	}
 
-	signatures := core.NewSignatures()
-
-	err = yaml.Unmarshal([]byte(signatureData), signatures)
-	if err != nil {
-		return nil, err
-	}
-
-	severityFilter, _ := cmd.Flags().GetString("severity-filter")
-	if severityFilter != "" {
-		signatures.FilterBySeverity(severityFilter)
-	}
-
-	pluginFilters, _ := cmd.Flags().GetStringSlice("plugin-filters")
-	if len(pluginFilters) > 0 {
-		signatures.FilterByNames(pluginFilters)
-	}
-
-	for _, plugin := range signatures.Plugins {
-		if plugin.Endpoint == "" {
-			if len(plugin.Endpoints) > 0 {
-				return nil, fmt.Errorf("URI and URIs can't be set at the same time in plugin checks. Stopping execution")
-			}
-		}
-		for _, check := range plugin.Checks {
-			if check.Description == "" {
-				return nil, fmt.Errorf("Missing or empty description field in %s plugin checks. Stopping execution", check.Name)
-			}
-			if check.Remediation == "" {
-				return nil, fmt.Errorf("Missing or empty remediation field in %s plugin checks. Stopping execution", check.Name)
-			}
-			if check.Severity == "" {
-				return nil, fmt.Errorf("Missing severity field in %s plugin checks. Stopping execution", check.Name)
-			}
-			if !core.ValidSeverity(check.Severity) {
-				return nil, fmt.Errorf("Invalid severity : %s. Please use : %s", check.Severity, core.SeveritiesAsString())
-			}
-			for _, header := range check.Headers {
-				if len(strings.Split(header, ":")) < 2 {
-					return nil, fmt.Errorf("Invalid header format : %s. Format should be KEY:VALUE", header)
-				}
-			}
-		}
-	}
-
-	return signatures, nil
+	return core.ParseSignatures(signatureData)
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// stateSaveInterval is the time between two saves of the state file during a scan
+const stateSaveInterval = 30 * time.Second
+
+// parseState returns the file the progress of the scan is saved to and, when resuming, the state read from it
+func parseState(cmd *cobra.Command) (string, *core.ScanState, error) {
+	stateFile, err := cmd.Flags().GetString("state-file")
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value for state-file: %v", err)
+	}
+	resume, err := cmd.Flags().GetString("resume")
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value for resume: %v", err)
+	}
+	if resume == "" {
+		return stateFile, nil, nil
+	}
+	data, err := ioutil.ReadFile(resume)
+	if err != nil {
+		return "", nil, fmt.Errorf("Could not read state file : %v", err)
+	}
+	state, err := core.LoadScanState(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if stateFile == "" {
+		stateFile = resume
+	}
+	return stateFile, state, nil
+}
+
+// saveState writes the state to a temporary file first, so that an interruption never leaves a truncated state file
+func saveState(path string, state *core.ScanState) error {
+	data, err := state.Marshal()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveStatePeriodically saves the state every stateSaveInterval until the returned function is called
+func saveStatePeriodically(path string, state *core.ScanState) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(stateSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := saveState(path, state); err != nil {
+					log.Error("Could not save the scan state : ", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
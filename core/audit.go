@@ -0,0 +1,282 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minHSTSMaxAge is the lowest Strict-Transport-Security max-age not reported as weak, 180 days
+const minHSTSMaxAge = 15552000
+
+// AuditRule is a built-in passive check of the security headers of a response
+type AuditRule struct {
+	Check *Check
+	// issue returns true when the response is affected, https telling if the URL was requested over TLS
+	issue func(resp *internal.HTTPResponse, https bool) bool
+}
+
+// HeaderAudit reports missing or weak security headers on the responses fetched during a scan.
+// Only successful and redirect responses are audited, a finding being reported once per rule and target.
+type HeaderAudit struct {
+	Rules []*AuditRule
+}
+
+// DefaultAuditRules returns the built-in security header rules with their default severities
+func DefaultAuditRules() []*AuditRule {
+	return []*AuditRule{
+		{
+			Check: &Check{
+				ID:          "missing-hsts",
+				Name:        "Missing Strict-Transport-Security header",
+				Severity:    "Low",
+				Tags:        []string{"security-headers"},
+				Description: "The HTTPS response doesn't tell browsers to only connect over HTTPS",
+				Remediation: "Set the Strict-Transport-Security header with a max-age of at least 180 days",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Strict-Transport-Security"},
+				CWE:         []string{"CWE-319"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return https && headerValue(resp, "Strict-Transport-Security") == ""
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "weak-hsts",
+				Name:        "Weak Strict-Transport-Security header",
+				Severity:    "Low",
+				Tags:        []string{"security-headers"},
+				Description: "The Strict-Transport-Security max-age is missing or lower than 180 days",
+				Remediation: "Set the Strict-Transport-Security max-age to at least 15552000 seconds",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Strict-Transport-Security"},
+				CWE:         []string{"CWE-319"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				hsts := headerValue(resp, "Strict-Transport-Security")
+				return https && hsts != "" && hstsMaxAge(hsts) < minHSTSMaxAge
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "missing-csp",
+				Name:        "Missing Content-Security-Policy header",
+				Severity:    "Low",
+				Tags:        []string{"security-headers"},
+				Description: "The HTML page doesn't restrict the sources of its scripts and resources",
+				Remediation: "Set a Content-Security-Policy header",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/CSP"},
+				CWE:         []string{"CWE-693"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return isHTML(resp) && headerValue(resp, "Content-Security-Policy") == ""
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "weak-csp",
+				Name:        "Weak Content-Security-Policy header",
+				Severity:    "Low",
+				Tags:        []string{"security-headers"},
+				Description: "The Content-Security-Policy allows inline scripts or eval, defeating its protection against XSS",
+				Remediation: "Remove 'unsafe-inline' and 'unsafe-eval' from the script-src and default-src directives, use nonces or hashes instead",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Security-Policy/script-src"},
+				CWE:         []string{"CWE-693"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return unsafeCSP(headerValue(resp, "Content-Security-Policy"))
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "missing-x-frame-options",
+				Name:        "Missing clickjacking protection",
+				Severity:    "Low",
+				Tags:        []string{"security-headers"},
+				Description: "The HTML page sets neither X-Frame-Options nor a Content-Security-Policy frame-ancestors directive",
+				Remediation: "Set X-Frame-Options to DENY or SAMEORIGIN, or use the frame-ancestors directive",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Frame-Options"},
+				CWE:         []string{"CWE-1021"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return isHTML(resp) && headerValue(resp, "X-Frame-Options") == "" &&
+					cspDirective(headerValue(resp, "Content-Security-Policy"), "frame-ancestors") == nil
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "missing-x-content-type-options",
+				Name:        "Missing X-Content-Type-Options header",
+				Severity:    "Informational",
+				Tags:        []string{"security-headers"},
+				Description: "Browsers may guess the type of the response instead of trusting its Content-Type",
+				Remediation: "Set the X-Content-Type-Options header to nosniff",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Content-Type-Options"},
+				CWE:         []string{"CWE-693"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return hasContent(resp) && !strings.EqualFold(headerValue(resp, "X-Content-Type-Options"), "nosniff")
+			},
+		},
+		{
+			Check: &Check{
+				ID:          "missing-referrer-policy",
+				Name:        "Missing Referrer-Policy header",
+				Severity:    "Informational",
+				Tags:        []string{"security-headers"},
+				Description: "The HTML page may leak its URL to other sites through the Referer header",
+				Remediation: "Set the Referrer-Policy header, for instance to strict-origin-when-cross-origin",
+				References:  []string{"https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Referrer-Policy"},
+			},
+			issue: func(resp *internal.HTTPResponse, https bool) bool {
+				return isHTML(resp) && headerValue(resp, "Referrer-Policy") == ""
+			},
+		},
+	}
+}
+
+// NewHeaderAudit returns an audit running the default rules, severities overriding the severity of rules by id
+func NewHeaderAudit(severities map[string]string) (*HeaderAudit, error) {
+	audit := &HeaderAudit{Rules: DefaultAuditRules()}
+	for id, severity := range severities {
+		rule := audit.rule(id)
+		if rule == nil {
+			return nil, fmt.Errorf("Invalid header audit rule : %s. Please use : %s", id, strings.Join(audit.RuleIDs(), ", "))
+		}
+		if !ValidSeverity(severity) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, SeveritiesAsString())
+		}
+		rule.Check.Severity = severity
+	}
+	return audit, nil
+}
+
+// RuleIDs returns the ids of the rules of the audit
+func (a *HeaderAudit) RuleIDs() []string {
+	ids := make([]string, len(a.Rules))
+	for i, rule := range a.Rules {
+		ids[i] = rule.Check.ID
+	}
+	return ids
+}
+
+func (a *HeaderAudit) rule(id string) *AuditRule {
+	for _, rule := range a.Rules {
+		if rule.Check.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Filter keeps only the rules selected by the filter
+func (a *HeaderAudit) Filter(filter *Filter) {
+	a.Rules = a.Filtered(filter).Rules
+}
+
+// Filtered returns the audit keeping only the rules selected by the filter, leaving a untouched
+func (a *HeaderAudit) Filtered(filter *Filter) *HeaderAudit {
+	var rules []*AuditRule
+	for _, rule := range a.Rules {
+		if filter.Match(rule.Check) {
+			rules = append(rules, rule)
+		}
+	}
+	return &HeaderAudit{Rules: rules}
+}
+
+// Audit returns the rules the response breaks, url being the requested URL and the response
+// telling the final one of the redirect chain
+func (a *HeaderAudit) Audit(url string, resp *internal.HTTPResponse) []*Check {
+	if resp.StatusCode >= 400 {
+		return nil
+	}
+	if resp.URL != "" {
+		url = resp.URL
+	}
+	https := strings.HasPrefix(strings.ToLower(url), "https://")
+	var checks []*Check
+	for _, rule := range a.Rules {
+		if rule.issue(resp, https) {
+			checks = append(checks, rule.Check)
+		}
+	}
+	return checks
+}
+
+// auditFindings remembers the rules already reported for each target during a scan
+type auditFindings struct {
+	mux      sync.Mutex
+	reported map[string]bool
+}
+
+// report returns true the first time the rule is reported for the target
+func (f *auditFindings) report(target string, check *Check) bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	key := target + " " + check.ID
+	if f.reported[key] {
+		return false
+	}
+	f.reported[key] = true
+	return true
+}
+
+func headerValue(resp *internal.HTTPResponse, name string) string {
+	values, _ := headerValues(resp.Header, name)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
+
+func isHTML(resp *internal.HTTPResponse) bool {
+	return strings.Contains(strings.ToLower(headerValue(resp, "Content-Type")), "text/html")
+}
+
+// hasContent returns true for the successful responses with a body and a Content-Type, the ones browsers could sniff
+func hasContent(resp *internal.HTTPResponse) bool {
+	return resp.StatusCode >= 200 && resp.StatusCode < 300 && resp.Body != "" && headerValue(resp, "Content-Type") != ""
+}
+
+var maxAgeDirective = regexp.MustCompile(`(?i)max-age\s*=\s*"?(\d+)"?`)
+
+// hstsMaxAge returns the max-age of a Strict-Transport-Security header, -1 if it can't be read
+func hstsMaxAge(hsts string) int {
+	match := maxAgeDirective.FindStringSubmatch(hsts)
+	if match == nil {
+		return -1
+	}
+	maxAge, err := strconv.Atoi(match[1])
+	if err != nil {
+		return -1
+	}
+	return maxAge
+}
+
+// cspDirective returns the sources of a Content-Security-Policy directive, nil if it isn't set
+func cspDirective(csp string, name string) []string {
+	for _, directive := range strings.Split(csp, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) > 0 && strings.EqualFold(fields[0], name) {
+			return fields[1:len(fields):len(fields)]
+		}
+	}
+	return nil
+}
+
+// unsafeCSP returns true if the policy allows inline scripts or eval, script-src falling back to default-src
+func unsafeCSP(csp string) bool {
+	sources := cspDirective(csp, "script-src")
+	if sources == nil {
+		sources = cspDirective(csp, "default-src")
+	}
+	for _, source := range sources {
+		if strings.EqualFold(source, "'unsafe-inline'") || strings.EqualFold(source, "'unsafe-eval'") {
+			return true
+		}
+	}
+	return false
+}
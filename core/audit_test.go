@@ -0,0 +1,168 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/mock"
+	"net/http"
+	"sort"
+	"testing"
+)
+
+func TestHeaderAudit(t *testing.T) {
+	secure := http.Header{
+		"Content-Type":              []string{"text/html; charset=utf-8"},
+		"Strict-Transport-Security": []string{"max-age=31536000; includeSubDomains"},
+		"Content-Security-Policy":   []string{"default-src 'self'; frame-ancestors 'none'"},
+		"X-Content-Type-Options":    []string{"nosniff"},
+		"Referrer-Policy":           []string{"no-referrer"},
+	}
+	with := func(name string, value string) http.Header {
+		header := http.Header{}
+		for k, v := range secure {
+			header[k] = v
+		}
+		if value == "" {
+			header.Del(name)
+		} else {
+			header.Set(name, value)
+		}
+		return header
+	}
+	var tests = map[string]struct {
+		url    string
+		resp   *internal.HTTPResponse
+		issues []string
+	}{
+		"Secure":                 {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: secure}},
+		"Missing HSTS":           {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Strict-Transport-Security", "")}, issues: []string{"missing-hsts"}},
+		"No HSTS over HTTP":      {url: "http://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Strict-Transport-Security", "")}},
+		"Redirected to HTTPS":    {url: "http://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Strict-Transport-Security", ""), URL: "https://example.com/"}, issues: []string{"missing-hsts"}},
+		"Redirected to HTTP":     {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Strict-Transport-Security", ""), URL: "http://example.com/"}},
+		"Short HSTS max-age":     {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Strict-Transport-Security", "max-age=3600")}, issues: []string{"weak-hsts"}},
+		"Missing CSP":            {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Content-Security-Policy", "")}, issues: []string{"missing-csp", "missing-x-frame-options"}},
+		"Unsafe inline script":   {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline'; frame-ancestors 'self'")}, issues: []string{"weak-csp"}},
+		"X-Frame-Options only":   {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("Content-Security-Policy", "default-src 'self'")}, issues: []string{"missing-x-frame-options"}},
+		"Sniffing allowed":       {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("X-Content-Type-Options", ""), Body: "<html>"}, issues: []string{"missing-x-content-type-options"}},
+		"Redirect not sniffed":   {url: "http://example.com/", resp: &internal.HTTPResponse{StatusCode: 301, Header: http.Header{"Location": []string{"https://example.com/"}}}},
+		"Empty body not sniffed": {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: with("X-Content-Type-Options", "")}},
+		"Not an HTML page":       {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Content-Type": []string{"application/json"}, "Strict-Transport-Security": []string{"max-age=31536000"}, "X-Content-Type-Options": []string{"nosniff"}}}},
+		"Error page is ignored":  {url: "https://example.com/", resp: &internal.HTTPResponse{StatusCode: 404, Header: http.Header{}}},
+	}
+
+	audit, err := core.NewHeaderAudit(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var have []string
+			for _, check := range audit.Audit(tc.url, tc.resp) {
+				have = append(have, check.ID)
+			}
+			sort.Strings(have)
+			if !core.SliceStringEqual(have, tc.issues) {
+				t.Errorf("expected: %v, got: %v", tc.issues, have)
+			}
+		})
+	}
+}
+
+func TestNewHeaderAudit(t *testing.T) {
+	var tests = map[string]struct {
+		severities map[string]string
+		valid      bool
+	}{
+		"Default severities": {valid: true},
+		"Override":           {severities: map[string]string{"missing-hsts": "High"}, valid: true},
+		"Unknown rule":       {severities: map[string]string{"missing-foo": "High"}, valid: false},
+		"Invalid severity":   {severities: map[string]string{"missing-hsts": "Critical"}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			audit, err := core.NewHeaderAudit(tc.severities)
+			if !tc.valid {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, rule := range audit.Rules {
+				if severity, ok := tc.severities[rule.Check.ID]; ok && rule.Check.Severity != severity {
+					t.Errorf("expected severity %s for %s, got: %s", severity, rule.Check.ID, rule.Check.Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestScanHeaderAudit(t *testing.T) {
+	fetcher := mock.FakeFetcherWithoutNetclient{
+		"https://site/":            &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Content-Type": []string{"application/json"}}},
+		"https://site/?query=test": &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Content-Type": []string{"application/json"}}},
+	}
+	scanner := core.NewScanner(fetcher, fetcher, mock.FakeSignatures, 1)
+	audit, err := core.NewHeaderAudit(map[string]string{"missing-hsts": "Medium"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	audit.Filter(&core.Filter{ExcludeIDs: []string{"missing-x-content-type-options"}})
+	scanner.HeaderAudit = audit
+
+	output, err := scanner.Scan(context.Background(), []string{"https://site"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := 0
+	for _, o := range output {
+		switch o.ID {
+		case "missing-hsts":
+			found++
+			if o.Severity != "Medium" {
+				t.Errorf("expected severity Medium, got: %s", o.Severity)
+			}
+		case "missing-x-content-type-options":
+			t.Errorf("excluded rule reported: %v", o)
+		}
+	}
+	if found != 1 {
+		t.Errorf("expected missing-hsts to be reported once for the target, got: %d", found)
+	}
+}
+
+func TestScanHeaderAuditOncePerTarget(t *testing.T) {
+	fetcher := mock.FakeFetcherWithoutNetclient{
+		"https://site/.git/config": &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Content-Type": []string{"text/plain"}}},
+		"https://site/.env":        &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Content-Type": []string{"text/plain"}}},
+	}
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoints: []string{"/.git/config", "/.env"}}}}
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 2)
+	audit, err := core.NewHeaderAudit(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scanner.HeaderAudit = audit
+
+	output, err := scanner.Scan(context.Background(), []string{"https://site"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output) == 0 {
+		t.Fatalf("expected header audit findings")
+	}
+	reported := make(map[string]bool)
+	for _, o := range output {
+		if reported[o.ID] {
+			t.Errorf("expected %s to be reported once for the target, got: %v", o.ID, output)
+		}
+		reported[o.ID] = true
+		if _, fetched := fetcher[o.URL]; !fetched || o.URL != "https://site"+o.Endpoint {
+			t.Errorf("expected the finding to be reported on the response lacking the header, got: %v", o)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+// Statuses of the findings of a scan compared to a baseline scan
+const (
+	StatusNew      = "new"
+	StatusPresent  = "present"
+	StatusResolved = "resolved"
+)
+
+// key identifies a finding across scans, by check and URL
+func (o Output) key() string {
+	id := o.ID
+	if id == "" {
+		id = o.Name
+	}
+	return o.URL + " " + o.Endpoint + " " + id
+}
+
+// CompareBaseline sets the status of the outputs, new or still present in the baseline, and appends the findings
+// of the baseline that weren't found again as resolved. Findings already resolved in the baseline are ignored.
+func CompareBaseline(outputs []Output, baseline []Output) []Output {
+	previous := make(map[string]bool)
+	for _, output := range baseline {
+		if output.Status != StatusResolved {
+			previous[output.key()] = true
+		}
+	}
+	current := make(map[string]bool)
+	compared := make([]Output, 0, len(outputs))
+	for _, output := range outputs {
+		current[output.key()] = true
+		output.Status = StatusNew
+		if previous[output.key()] {
+			output.Status = StatusPresent
+		}
+		compared = append(compared, output)
+	}
+	for _, output := range baseline {
+		if previous[output.key()] && !current[output.key()] {
+			current[output.key()] = true
+			output.Status = StatusResolved
+			// the completeness is the one of the current scan
+			output.Incomplete = false
+			compared = append(compared, output)
+		}
+	}
+	return compared
+}
@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	finding := func(url string, id string, status string) core.Output {
+		return core.Output{URL: url, Endpoint: "/", ID: id, Name: id, Status: status}
+	}
+	var tests = map[string]struct {
+		outputs  []core.Output
+		baseline []core.Output
+		want     []core.Output
+	}{
+		"Empty baseline": {
+			outputs: []core.Output{finding("http://a", "git", "")},
+			want:    []core.Output{finding("http://a", "git", core.StatusNew)},
+		},
+		"Still present": {
+			outputs:  []core.Output{finding("http://a", "git", "")},
+			baseline: []core.Output{finding("http://a", "git", "")},
+			want:     []core.Output{finding("http://a", "git", core.StatusPresent)},
+		},
+		"Same check on another URL": {
+			outputs:  []core.Output{finding("http://a", "git", "")},
+			baseline: []core.Output{finding("http://b", "git", "")},
+			want:     []core.Output{finding("http://a", "git", core.StatusNew), finding("http://b", "git", core.StatusResolved)},
+		},
+		"Resolved": {
+			baseline: []core.Output{finding("http://a", "git", core.StatusNew), finding("http://a", "svn", core.StatusPresent)},
+			want:     []core.Output{finding("http://a", "git", core.StatusResolved), finding("http://a", "svn", core.StatusResolved)},
+		},
+		"Already resolved in the baseline": {
+			outputs:  []core.Output{finding("http://a", "git", "")},
+			baseline: []core.Output{finding("http://a", "git", core.StatusResolved), finding("http://a", "svn", core.StatusResolved)},
+			want:     []core.Output{finding("http://a", "git", core.StatusNew)},
+		},
+		"Duplicated in the baseline": {
+			baseline: []core.Output{finding("http://a", "git", ""), finding("http://a", "git", "")},
+			want:     []core.Output{finding("http://a", "git", core.StatusResolved)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.CompareBaseline(tc.outputs, tc.baseline)
+			if len(have) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, have)
+			}
+			for i := range have {
+				if have[i].URL != tc.want[i].URL || have[i].ID != tc.want[i].ID || have[i].Status != tc.want[i].Status {
+					t.Errorf("expected: %v, got: %v", tc.want, have)
+				}
+			}
+		})
+	}
+}
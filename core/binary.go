@@ -0,0 +1,88 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// fileSignature is a magic number found at offset in the files of a type
+type fileSignature struct {
+	offset int
+	magic  string
+}
+
+var fileTypes = map[string][]fileSignature{
+	"7z":        {{0, "7z\xbc\xaf\x27\x1c"}},
+	"bzip2":     {{0, "BZh"}},
+	"elf":       {{0, "\x7fELF"}},
+	"gif":       {{0, "GIF87a"}, {0, "GIF89a"}},
+	"git-index": {{0, "DIRC"}},
+	"gzip":      {{0, "\x1f\x8b"}},
+	"jpeg":      {{0, "\xff\xd8\xff"}},
+	"pdf":       {{0, "%PDF-"}},
+	"png":       {{0, "\x89PNG\r\n\x1a\n"}},
+	"rar":       {{0, "Rar!\x1a\x07"}},
+	"sqlite":    {{0, "SQLite format 3\x00"}},
+	"tar":       {{257, "ustar"}},
+	"xz":        {{0, "\xfd7zXZ\x00"}},
+	"zip":       {{0, "PK\x03\x04"}, {0, "PK\x05\x06"}},
+}
+
+// FileTypes returns the file types that can be detected, sorted
+func FileTypes() []string {
+	types := make([]string, 0, len(fileTypes))
+	for fileType := range fileTypes {
+		types = append(types, fileType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// matchFileType returns true if the body starts like the files of the type
+func matchFileType(body string, fileType string) bool {
+	for _, signature := range fileTypes[strings.ToLower(fileType)] {
+		if len(body) >= signature.offset && strings.HasPrefix(body[signature.offset:], signature.magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileTypeLength returns the number of bytes at the start of a body needed to detect the file type
+func fileTypeLength(fileType string) int {
+	length := 0
+	for _, signature := range fileTypes[strings.ToLower(fileType)] {
+		if end := signature.offset + len(signature.magic); end > length {
+			length = end
+		}
+	}
+	return length
+}
+
+// decodeHex reads a byte sequence written in hexadecimal, spaces being allowed between bytes
+func decodeHex(s string) (string, error) {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return "", fmt.Errorf("Invalid hex sequence : %s", s)
+	}
+	return string(b), nil
+}
+
+// matchContentType returns true if the media type of the Content-Type header is one of types,
+// a type ending with /* matching any subtype
+func matchContentType(contentType string, types []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if mediaType == t || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
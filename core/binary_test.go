@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+)
+
+func TestBinaryMatchers(t *testing.T) {
+	sqlite := "SQLite format 3\x00\x10\x00\x01\x01"
+	zip := "PK\x03\x04\x14\x00\x00\x00\x08\x00"
+	var tests = map[string]struct {
+		matchers core.Matchers
+		body     string
+		want     bool
+	}{
+		"Hex sequence":             {matchers: core.Matchers{Hex: []string{"504b0304"}}, body: zip, want: true},
+		"Hex with spaces":          {matchers: core.Matchers{Hex: []string{"00 10 00 01"}}, body: sqlite, want: true},
+		"Hex sequences all needed": {matchers: core.Matchers{Hex: []string{"504b0304", "ffff"}}, body: zip, want: false},
+		"SQLite file":              {matchers: core.Matchers{FileType: "sqlite"}, body: sqlite, want: true},
+		"Not a SQLite file":        {matchers: core.Matchers{FileType: "sqlite"}, body: zip, want: false},
+		"ZIP file":                 {matchers: core.Matchers{FileType: "ZIP"}, body: zip, want: true},
+		"Empty ZIP file":           {matchers: core.Matchers{FileType: "zip"}, body: "PK\x05\x06" + string(make([]byte, 18)), want: true},
+		"Git index":                {matchers: core.Matchers{FileType: "git-index"}, body: "DIRC\x00\x00\x00\x02", want: true},
+		"Tar magic at offset":      {matchers: core.Matchers{FileType: "tar"}, body: string(make([]byte, 257)) + "ustar\x0000", want: true},
+		"Body shorter than offset": {matchers: core.Matchers{FileType: "tar"}, body: "ustar", want: false},
+		"HTML page":                {matchers: core.Matchers{FileType: "gzip"}, body: "<html></html>", want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &internal.HTTPResponse{StatusCode: 200, Body: tc.body}
+			if have := tc.matchers.Match(resp); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestContentTypeMatcher(t *testing.T) {
+	var tests = map[string]struct {
+		contentType string
+		types       []string
+		want        bool
+	}{
+		"Exact type":       {contentType: "application/json", types: []string{"application/json"}, want: true},
+		"With parameters":  {contentType: "application/json; charset=utf-8", types: []string{"application/json"}, want: true},
+		"Case-insensitive": {contentType: "Application/JSON", types: []string{"application/json"}, want: true},
+		"One of the types": {contentType: "application/zip", types: []string{"application/json", "application/zip"}, want: true},
+		"Wildcard subtype": {contentType: "image/png", types: []string{"image/*"}, want: true},
+		"Other type":       {contentType: "text/html", types: []string{"image/*", "application/json"}, want: false},
+		"Missing header":   {contentType: "", types: []string{"text/html"}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &internal.HTTPResponse{StatusCode: 200, Header: http.Header{}}
+			if tc.contentType != "" {
+				resp.Header.Set("Content-Type", tc.contentType)
+			}
+			matchers := core.Matchers{ContentType: tc.types}
+			if have := matchers.Match(resp); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestBinaryMatchersValidate(t *testing.T) {
+	var tests = map[string]struct {
+		matchers core.Matchers
+		valid    bool
+	}{
+		"Valid":                {matchers: core.Matchers{Hex: []string{"50 4b"}, FileType: "zip", ContentType: []string{"application/zip"}}, valid: true},
+		"Invalid hex":          {matchers: core.Matchers{Hex: []string{"50 4"}}, valid: false},
+		"Not hex":              {matchers: core.Matchers{Hex: []string{"PK"}}, valid: false},
+		"Unknown file type":    {matchers: core.Matchers{FileType: "docx"}, valid: false},
+		"Invalid content type": {matchers: core.Matchers{ContentType: []string{"text/"}}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.matchers.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"gochopchop/internal"
+	"strings"
+)
+
+// allMatchers returns the matchers of the check and of every node of its condition
+func (check *Check) allMatchers() []*Matchers {
+	matchers := []*Matchers{&check.Matchers}
+	if check.Condition != nil {
+		matchers = append(matchers, check.Condition.allMatchers()...)
+	}
+	return matchers
+}
+
+func (c *Condition) allMatchers() []*Matchers {
+	matchers := []*Matchers{&c.Matchers}
+	for _, condition := range append(c.All, c.Any...) {
+		matchers = append(matchers, condition.allMatchers()...)
+	}
+	if c.Not != nil {
+		matchers = append(matchers, c.Not.allMatchers()...)
+	}
+	return matchers
+}
+
+// bodySearch remembers which strings have been found in a body read progressively
+type bodySearch struct {
+	found    map[string]bool
+	searched int
+}
+
+// update searches the strs not found yet in the part of the body read since the last update
+func (b *bodySearch) update(body string, strs []string) {
+	for _, str := range strs {
+		if b.found[str] {
+			continue
+		}
+		// the previous part overlaps the new one to find strings cut in two
+		start := b.searched - len(str) + 1
+		if start < 0 {
+			start = 0
+		}
+		if strings.Contains(body[start:], str) {
+			b.found[str] = true
+		}
+	}
+	b.searched = len(body)
+}
+
+func (b *bodySearch) foundAll(strs []string) bool {
+	for _, str := range strs {
+		if !b.found[str] {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyNeeds is what a check searches in the body
+type bodyNeeds struct {
+	// strs are searched in the text body and raw in the raw one
+	strs []string
+	raw  []string
+	// prefix is the number of bytes at the start of the raw body needed to detect file types
+	prefix int
+}
+
+// bodyDecider returns a function telling if the body read so far is enough to evaluate every check of the plugin,
+// nil if the whole body is always needed.
+// The outcome of a check can't change anymore once the matchers set on the check that don't depend
+// on the body fail, or once every string it searches has been found and enough bytes were read to detect file types.
+func (plugin *Plugin) bodyDecider() func(resp *internal.HTTPResponse) bool {
+	needs := make([]bodyNeeds, len(plugin.Checks))
+	var textStrings, rawStrings []string
+	for i, check := range plugin.Checks {
+		for _, m := range check.allMatchers() {
+			if m.Truncated != nil {
+				return nil
+			}
+			needs[i].strs = append(needs[i].strs, m.textStrings()...)
+			needs[i].raw = append(needs[i].raw, m.rawStrings()...)
+			if l := fileTypeLength(m.FileType); l > needs[i].prefix {
+				needs[i].prefix = l
+			}
+		}
+		textStrings = append(textStrings, needs[i].strs...)
+		rawStrings = append(rawStrings, needs[i].raw...)
+	}
+
+	text := &bodySearch{found: make(map[string]bool)}
+	raw := &bodySearch{found: make(map[string]bool)}
+	return func(resp *internal.HTTPResponse) bool {
+		text.update(resp.Body, textStrings)
+		raw.update(resp.Raw(), rawStrings)
+		for i, check := range plugin.Checks {
+			if !check.Matchers.matchResponse(resp) {
+				continue
+			}
+			if len(resp.Raw()) < needs[i].prefix || !text.foundAll(needs[i].strs) || !raw.foundAll(needs[i].raw) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,49 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/mock"
+	"strings"
+	"testing"
+)
+
+func TestScanStopsReading(t *testing.T) {
+	yes := true
+	body := "0123456789 begin of the page 0123456789" + strings.Repeat(".", 1000) + "secret"
+	var tests = map[string]struct {
+		check *core.Check
+		match bool
+		full  bool
+	}{
+		"Strings found early":       {check: &core.Check{Matchers: core.Matchers{MustMatchAll: []string{"begin", "page"}}}, match: true},
+		"String at the end":         {check: &core.Check{Matchers: core.Matchers{MustMatchOne: []string{"secret"}}}, match: true, full: true},
+		"String cut between chunks": {check: &core.Check{Matchers: core.Matchers{MustMatchAll: []string{"of the"}}}, match: true},
+		"No match needs the body":   {check: &core.Check{Matchers: core.Matchers{MustNotMatch: []string{"secret"}}}, match: false, full: true},
+		"Failed status code":        {check: &core.Check{Matchers: core.Matchers{StatusCode: core.NewStatusCodes(404), MustNotMatch: []string{"secret"}}}, match: false},
+		"Header only":               {check: &core.Check{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Name: "Server"}}}}, match: true},
+		"Condition strings":         {check: &core.Check{Condition: &core.Condition{Not: &core.Condition{Matchers: core.Matchers{MustMatchOne: []string{"secret"}}}}}, match: false, full: true},
+		"File type decided early":   {check: &core.Check{Matchers: core.Matchers{FileType: "tar"}}, match: false},
+		"Truncation needs the body": {check: &core.Check{Matchers: core.Matchers{MustMatchOne: []string{"begin"}, Truncated: &yes}}, match: false, full: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc.check.Name = name
+			fetcher := mock.NewFetcher(mock.FakeFetcherWithoutNetclient{
+				"http://site/": {StatusCode: 200, Header: map[string][]string{"Server": {"test"}}, Body: body},
+			}, mock.WithChunks(10))
+			signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{tc.check}}}}
+			output, err := core.NewScanner(fetcher, fetcher, signatures, 1).Scan(context.Background(), []string{"http://site"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if have := len(output) == 1; have != tc.match {
+				t.Errorf("expected match: %v, got: %v", tc.match, output)
+			}
+			if have := fetcher.Read("http://site/") == len(body); have != tc.full {
+				t.Errorf("expected whole body read: %v, got %d bytes", tc.full, fetcher.Read("http://site/"))
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NotRun is a plugin skipped on a URL because the time budget of its target ran out
+type NotRun struct {
+	URL      string   `json:"url"`
+	Endpoint string   `json:"endpoint"`
+	Checks   []string `json:"checks"`
+}
+
+// NotRunError is returned by Scan along with the findings when plugins were skipped
+// because of the time budget of their target, the other targets being fully scanned
+type NotRunError struct {
+	NotRun []NotRun
+}
+
+func (e *NotRunError) Error() string {
+	return fmt.Sprintf("%d plugins not run, the time budget of their target ran out", len(e.NotRun))
+}
+
+// targetBudgets gives each target of a scan a context whose deadline is its time budget,
+// counted from the first job of the target
+type targetBudgets struct {
+	mux     sync.Mutex
+	budget  time.Duration
+	ctxs    map[string]context.Context
+	cancels []context.CancelFunc
+	notRun  []NotRun
+}
+
+// context returns the context of the jobs of the target, the scan context itself without budget
+func (b *targetBudgets) context(ctx context.Context, target string) context.Context {
+	if b.budget <= 0 {
+		return ctx
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if targetCtx, ok := b.ctxs[target]; ok {
+		return targetCtx
+	}
+	targetCtx, cancel := context.WithTimeout(ctx, b.budget)
+	b.ctxs[target] = targetCtx
+	b.cancels = append(b.cancels, cancel)
+	return targetCtx
+}
+
+// skip records a job not run because the budget of its target ran out
+func (b *targetBudgets) skip(job workerJob) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	ids := make([]string, len(job.plugin.Checks))
+	for i, check := range job.plugin.Checks {
+		ids[i] = check.ID
+	}
+	b.notRun = append(b.notRun, NotRun{URL: job.url, Endpoint: job.endpoint, Checks: ids})
+}
+
+// err returns the error of the scan: the one of its context first, then the jobs not run
+func (b *targetBudgets) err(ctx context.Context) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, cancel := range b.cancels {
+		cancel()
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(b.notRun) > 0 {
+		return &NotRunError{NotRun: b.notRun}
+	}
+	return nil
+}
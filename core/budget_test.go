@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func budgetSignatures(n int) *core.Signatures {
+	var plugins []*core.Plugin
+	for i := 0; i < n; i++ {
+		check := &core.Check{ID: fmt.Sprintf("check-%d", i), Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}
+		plugins = append(plugins, &core.Plugin{Endpoint: fmt.Sprintf("/%d", i), Checks: []*core.Check{check}})
+	}
+	return &core.Signatures{Plugins: plugins}
+}
+
+func TestScanTargetBudget(t *testing.T) {
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200), mock.WithDelay("http://slow", 40*time.Millisecond))
+	scanner := core.NewScanner(fetcher, fetcher, budgetSignatures(10), 1)
+	scanner.TargetBudget = 100 * time.Millisecond
+
+	output, err := scanner.Scan(context.Background(), []string{"http://slow", "http://fast"})
+	var notRun *core.NotRunError
+	if !errors.As(err, &notRun) {
+		t.Fatalf("expected a NotRunError, got: %v", err)
+	}
+	found := map[string]int{}
+	for _, o := range output {
+		found[strings.TrimSuffix(o.URL, o.Endpoint)]++
+	}
+	if found["http://fast"] != 10 {
+		t.Errorf("expected the fast target to be fully scanned, got %d findings", found["http://fast"])
+	}
+	if found["http://slow"] == 0 || found["http://slow"]+len(notRun.NotRun) != 10 {
+		t.Errorf("expected the slow target to be partly scanned, got %d findings and %d plugins not run", found["http://slow"], len(notRun.NotRun))
+	}
+	for _, job := range notRun.NotRun {
+		if !strings.HasPrefix(job.URL, "http://slow") || len(job.Checks) != 1 {
+			t.Errorf("unexpected plugin not run: %v", job)
+		}
+	}
+}
+
+func TestScanMaxDuration(t *testing.T) {
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200), mock.WithDelay("http://slow", time.Second))
+	scanner := core.NewScanner(fetcher, fetcher, budgetSignatures(10), 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	_, err := scanner.Scan(ctx, []string{"http://slow"})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the requests to be aborted at the deadline, took %s", elapsed)
+	}
+}
@@ -1,5 +1,11 @@
 package core
 
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
 // Struct for config flags
 type Config struct {
 	HTTP           HTTPConfig
@@ -7,12 +13,32 @@ type Config struct {
 	ExportFormats  []string
 	Urls           []string
 	ExportFilename string
-	SeverityFilter string
-	PluginFilter   []string
+	Filter         *Filter
 	Threads        int
+	// HeaderAudit enables the security header audit, HeaderAuditSeverities overriding the severity of its rules
+	HeaderAudit           bool
+	HeaderAuditSeverities map[string]string
+	// Soft404 is the soft-404 mode applied to findings on catch-all pages
+	Soft404 string
+	// Baseline is the JSON export of a previous scan the findings are compared to
+	Baseline string
+	// Suppressions are applied to the findings when set
+	Suppressions *Suppressions
+	// StateFile is where the progress of the scan is saved, State holding the progress of the scan resumed
+	StateFile string
+	State     *ScanState
+	// MaxDuration bounds the whole scan and TargetBudget the scan of each target, 0 meaning no limit
+	MaxDuration  time.Duration
+	TargetBudget time.Duration
 }
 
 type HTTPConfig struct {
 	Insecure bool
 	Timeout  int
+	// MaxBodySize is the number of bytes read at most from a response body, 0 meaning no limit
+	MaxBodySize int64
+	// Proxy is the proxy the requests go through, nil for a direct connection
+	Proxy *url.URL
+	// Headers are added to every request
+	Headers http.Header
 }
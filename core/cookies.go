@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// cookie flags that can be required to be missing
+var cookieFlags = []string{"secure", "httponly", "samesite"}
+
+// CookieMatcher is a condition on a cookie set by the response through Set-Cookie.
+// It matches when one cookie has the name, a value matching the regex and none of the missing flags.
+// A name ending with * matches the cookies whose name starts with the rest of it, "*" matching any cookie.
+type CookieMatcher struct {
+	Name         string   `yaml:"name"`
+	Regex        string   `yaml:"regex,omitempty"`
+	MissingFlags []string `yaml:"missing_flags,omitempty"`
+	regex        *regexp.Regexp
+}
+
+// plain CookieMatcher, without the YAML methods
+type cookieMatcher CookieMatcher
+
+func (c *CookieMatcher) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m cookieMatcher
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	*c = CookieMatcher(m)
+	return c.compile()
+}
+
+// Match returns true if one of the cookies satisfies the matcher, a regex being only matched once compiled by Validate
+func (c *CookieMatcher) Match(cookies []*http.Cookie) bool {
+	for _, cookie := range cookies {
+		if !c.matchName(cookie.Name) {
+			continue
+		}
+		if c.Regex != "" && (c.regex == nil || !c.regex.MatchString(cookie.Value)) {
+			continue
+		}
+		if c.hasFlag(cookie) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (c *CookieMatcher) matchName(name string) bool {
+	if strings.HasSuffix(c.Name, "*") {
+		return strings.HasPrefix(name, strings.TrimSuffix(c.Name, "*"))
+	}
+	return name == c.Name
+}
+
+// hasFlag returns true if the cookie sets one of the flags that should be missing
+func (c *CookieMatcher) hasFlag(cookie *http.Cookie) bool {
+	for _, flag := range c.MissingFlags {
+		switch strings.ToLower(flag) {
+		case "secure":
+			if cookie.Secure {
+				return true
+			}
+		case "httponly":
+			if cookie.HttpOnly {
+				return true
+			}
+		case "samesite":
+			if cookie.SameSite != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the matcher has no name, an invalid regex or an unknown flag
+func (c *CookieMatcher) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("Invalid cookie format : missing cookie name")
+	}
+	if err := c.compile(); err != nil {
+		return err
+	}
+	for _, flag := range c.MissingFlags {
+		if !containsString(cookieFlags, strings.ToLower(flag)) {
+			return fmt.Errorf("Invalid cookie flag : %s. Please use : %s", flag, strings.Join(cookieFlags, ", "))
+		}
+	}
+	return nil
+}
+
+// compile compiles the regex of the matcher unless it already is
+func (c *CookieMatcher) compile() error {
+	if c.Regex == "" || (c.regex != nil && c.regex.String() == c.Regex) {
+		return nil
+	}
+	regex, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return fmt.Errorf("Invalid regex for cookie %s : %v", c.Name, err)
+	}
+	c.regex = regex
+	return nil
+}
+
+func (c CookieMatcher) String() string {
+	s := c.Name
+	if c.Regex != "" {
+		s += fmt.Sprintf("=/%s/", c.Regex)
+	}
+	if len(c.MissingFlags) > 0 {
+		s += fmt.Sprintf(" (missing %s)", strings.Join(c.MissingFlags, ", "))
+	}
+	return s
+}
+
+func (self *CookieMatcher) Equals(cookie *CookieMatcher) bool {
+	return self.Name == cookie.Name && self.Regex == cookie.Regex && SliceStringEqual(self.MissingFlags, cookie.MissingFlags)
+}
+
+func cookieMatchersEqual(a, b []CookieMatcher) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func cookieMatchersString(cookies []CookieMatcher) string {
+	values := make([]string, len(cookies))
+	for i, c := range cookies {
+		values[i] = c.String()
+	}
+	return quoteAll(values)
+}
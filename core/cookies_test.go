@@ -0,0 +1,88 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestCookieMatcherMatch(t *testing.T) {
+	resp := &internal.HTTPResponse{
+		StatusCode: 200,
+		Header: http.Header{"Set-Cookie": []string{
+			"BIGipServerpool_web=1677787402.36895.0000; path=/",
+			"PHPSESSID=abc123; Path=/; HttpOnly",
+			"token=xyz; Secure; HttpOnly; SameSite=Strict",
+		}},
+	}
+	var tests = map[string]struct {
+		matcher core.CookieMatcher
+		want    bool
+	}{
+		"Name":                   {matcher: core.CookieMatcher{Name: "PHPSESSID"}, want: true},
+		"Missing cookie":         {matcher: core.CookieMatcher{Name: "JSESSIONID"}, want: false},
+		"Name prefix":            {matcher: core.CookieMatcher{Name: "BIGipServer*"}, want: true},
+		"Value regex":            {matcher: core.CookieMatcher{Name: "BIGipServer*", Regex: `^\d+\.\d+\.0000$`}, want: true},
+		"Value regex no match":   {matcher: core.CookieMatcher{Name: "PHPSESSID", Regex: `^\d+$`}, want: false},
+		"Missing secure":         {matcher: core.CookieMatcher{Name: "PHPSESSID", MissingFlags: []string{"secure"}}, want: true},
+		"HttpOnly set":           {matcher: core.CookieMatcher{Name: "PHPSESSID", MissingFlags: []string{"secure", "httponly"}}, want: false},
+		"All flags set":          {matcher: core.CookieMatcher{Name: "token", MissingFlags: []string{"SameSite"}}, want: false},
+		"Any cookie missing one": {matcher: core.CookieMatcher{Name: "*", MissingFlags: []string{"samesite"}}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.matcher.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if have := tc.matcher.Match(resp.Cookies()); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCookieMatcherUnmarshal(t *testing.T) {
+	var have core.CookieMatcher
+	err := yaml.Unmarshal([]byte(`{name: "BIGipServer*", regex: "^\\d", missing_flags: [secure]}`), &have)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := core.CookieMatcher{Name: "BIGipServer*", Regex: `^\d`, MissingFlags: []string{"secure"}}
+	if !have.Equals(&want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+
+	if err := yaml.Unmarshal([]byte(`{name: session, regex: "("}`), &have); err == nil {
+		t.Errorf("expected an error for an invalid regex")
+	}
+}
+
+func TestCookieMatcherValidate(t *testing.T) {
+	var tests = map[string]struct {
+		matcher core.CookieMatcher
+		valid   bool
+	}{
+		"Name only":     {matcher: core.CookieMatcher{Name: "session"}, valid: true},
+		"Known flags":   {matcher: core.CookieMatcher{Name: "session", MissingFlags: []string{"Secure", "HttpOnly", "SameSite"}}, valid: true},
+		"Missing name":  {matcher: core.CookieMatcher{Regex: "a"}, valid: false},
+		"Wildcard only": {matcher: core.CookieMatcher{Name: "*", MissingFlags: []string{"secure"}}, valid: true},
+		"Unknown flag":  {matcher: core.CookieMatcher{Name: "session", MissingFlags: []string{"domain"}}, valid: false},
+		"Invalid regex": {matcher: core.CookieMatcher{Name: "session", Regex: "("}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.matcher.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
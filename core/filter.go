@@ -0,0 +1,56 @@
+package core
+
+import "strings"
+
+// Filter selects the checks to run.
+// Every criterion must be satisfied: include lists keep the checks matching one of their values
+// (an empty include list keeps everything) and exclude lists drop the checks matching one of theirs.
+type Filter struct {
+	MinSeverity  string
+	Severities   []string
+	IDs          []string
+	ExcludeIDs   []string
+	Tags         []string
+	ExcludeTags  []string
+	Names        []string
+	ExcludeNames []string
+}
+
+// Match returns true if the check is selected by the filter
+func (f *Filter) Match(check *Check) bool {
+	if f.MinSeverity != "" && !SeverityReached(f.MinSeverity, check.Severity) {
+		return false
+	}
+	if len(f.Severities) > 0 && !containsString(f.Severities, check.Severity) {
+		return false
+	}
+	if len(f.IDs) > 0 && !containsString(f.IDs, check.ID) {
+		return false
+	}
+	if containsString(f.ExcludeIDs, check.ID) {
+		return false
+	}
+	if len(f.Tags) > 0 && !check.HasAnyTag(f.Tags) {
+		return false
+	}
+	if check.HasAnyTag(f.ExcludeTags) {
+		return false
+	}
+	if len(f.Names) > 0 && !nameContainsAny(check.Name, f.Names) {
+		return false
+	}
+	if nameContainsAny(check.Name, f.ExcludeNames) {
+		return false
+	}
+	return true
+}
+
+// names are matched case-insensitively on a part of the check name
+func nameContainsAny(name string, names []string) bool {
+	for _, n := range names {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(n)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/mock"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	var tests = map[string]struct {
+		filter *core.Filter
+		check  *core.Check
+		want   bool
+	}{
+		"Empty filter":                  {filter: &core.Filter{}, check: mock.FakeCheckHeaders, want: true},
+		"Min severity reached":          {filter: &core.Filter{MinSeverity: "Medium"}, check: mock.FakeCheckHeaders, want: true},
+		"Min severity not reached":      {filter: &core.Filter{MinSeverity: "Medium"}, check: mock.FakeCheckMatchOne, want: false},
+		"Exact severity":                {filter: &core.Filter{Severities: []string{"Low", "High"}}, check: mock.FakeCheckHeaders, want: true},
+		"Exact id":                      {filter: &core.Filter{IDs: []string{"headers"}}, check: mock.FakeCheckHeaders, want: true},
+		"Id is not a substring match":   {filter: &core.Filter{IDs: []string{"head"}}, check: mock.FakeCheckHeaders, want: false},
+		"Excluded id":                   {filter: &core.Filter{ExcludeIDs: []string{"headers"}}, check: mock.FakeCheckHeaders, want: false},
+		"Included tag":                  {filter: &core.Filter{Tags: []string{"CMS"}}, check: mock.FakeCheckHeaders, want: true},
+		"Missing tag":                   {filter: &core.Filter{Tags: []string{"iot"}}, check: mock.FakeCheckHeaders, want: false},
+		"Excluded tag":                  {filter: &core.Filter{Tags: []string{"cms"}, ExcludeTags: []string{"noisy"}}, check: mock.FakeCheckHeaders, want: false},
+		"Name part":                     {filter: &core.Filter{Names: []string{"header"}}, check: mock.FakeCheckHeaders, want: true},
+		"Excluded name part":            {filter: &core.Filter{ExcludeNames: []string{"header"}}, check: mock.FakeCheckHeaders, want: false},
+		"Min severity and excluded tag": {filter: &core.Filter{MinSeverity: "Medium", ExcludeTags: []string{"noisy"}}, check: mock.FakeCheckStatusCode200, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.filter.Match(tc.check)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// HeaderMatcher is a condition on a response header, header names being case-insensitive.
+// In signatures it is written either as a "Name:value" string, the value being optional,
+// or as a mapping with name, value (substring), regex and absent keys.
+type HeaderMatcher struct {
+	Name   string `yaml:"name"`
+	Value  string `yaml:"value,omitempty"`
+	Regex  string `yaml:"regex,omitempty"`
+	Absent bool   `yaml:"absent,omitempty"`
+	regex  *regexp.Regexp
+}
+
+// ParseHeaderMatcher reads the "Name:value" form, only the first colon separating the name from the value
+func ParseHeaderMatcher(s string) HeaderMatcher {
+	parts := strings.SplitN(s, ":", 2)
+	h := HeaderMatcher{Name: strings.TrimSpace(parts[0])}
+	if len(parts) == 2 {
+		h.Value = strings.TrimSpace(parts[1])
+	}
+	return h
+}
+
+// plain HeaderMatcher, without the YAML methods
+type headerMatcher HeaderMatcher
+
+func (h *HeaderMatcher) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*h = ParseHeaderMatcher(s)
+		return nil
+	}
+	var m headerMatcher
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	*h = HeaderMatcher(m)
+	return h.compile()
+}
+
+func (h HeaderMatcher) MarshalYAML() (interface{}, error) {
+	if h.Regex == "" && !h.Absent {
+		return h.String(), nil
+	}
+	return headerMatcher(h), nil
+}
+
+// Match returns true if the header satisfies the matcher, a regex being only matched once compiled by Validate
+func (h *HeaderMatcher) Match(header http.Header) bool {
+	values, found := headerValues(header, h.Name)
+	if h.Absent {
+		return !found
+	}
+	if !found {
+		return false
+	}
+	if h.Value == "" && h.Regex == "" {
+		return true
+	}
+	for _, value := range values {
+		if h.Value != "" && !strings.Contains(value, h.Value) {
+			continue
+		}
+		if h.Regex != "" && (h.regex == nil || !h.regex.MatchString(value)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Validate returns an error if the matcher has no name, an invalid regex or contradictory fields
+func (h *HeaderMatcher) Validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("Invalid header format : missing header name")
+	}
+	if h.Absent && (h.Value != "" || h.Regex != "") {
+		return fmt.Errorf("Invalid header format : %s can't be absent and have a value", h.Name)
+	}
+	return h.compile()
+}
+
+// compile compiles the regex of the matcher unless it already is
+func (h *HeaderMatcher) compile() error {
+	if h.Regex == "" || (h.regex != nil && h.regex.String() == h.Regex) {
+		return nil
+	}
+	regex, err := regexp.Compile(h.Regex)
+	if err != nil {
+		return fmt.Errorf("Invalid regex for header %s : %v", h.Name, err)
+	}
+	h.regex = regex
+	return nil
+}
+
+func (h HeaderMatcher) String() string {
+	switch {
+	case h.Absent:
+		return fmt.Sprintf("%s (absent)", h.Name)
+	case h.Regex != "" && h.Value != "":
+		return fmt.Sprintf("%s:%s /%s/", h.Name, h.Value, h.Regex)
+	case h.Regex != "":
+		return fmt.Sprintf("%s:/%s/", h.Name, h.Regex)
+	case h.Value != "":
+		return fmt.Sprintf("%s:%s", h.Name, h.Value)
+	}
+	return h.Name
+}
+
+func (self *HeaderMatcher) Equals(header *HeaderMatcher) bool {
+	return strings.EqualFold(self.Name, header.Name) && self.Value == header.Value &&
+		self.Regex == header.Regex && self.Absent == header.Absent
+}
+
+// headerValues looks the header up case-insensitively, whether the keys of the map are canonical or not
+func headerValues(header http.Header, name string) ([]string, bool) {
+	if values, found := header[http.CanonicalHeaderKey(name)]; found {
+		return values, true
+	}
+	for key, values := range header {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func headerMatchersEqual(a, b []HeaderMatcher) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func headerMatchersString(headers []HeaderMatcher) string {
+	values := make([]string, len(headers))
+	for i, h := range headers {
+		values[i] = h.String()
+	}
+	return quoteAll(values)
+}
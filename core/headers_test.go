@@ -0,0 +1,157 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestHeaderMatcherMatch(t *testing.T) {
+	header := http.Header{
+		"Content-Type": []string{"text/html; charset=utf-8"},
+		"Location":     []string{"https://example.com/login"},
+		"x-custom":     []string{"ok"},
+	}
+	var tests = map[string]struct {
+		matcher core.HeaderMatcher
+		want    bool
+	}{
+		"Presence":                {matcher: core.HeaderMatcher{Name: "Content-Type"}, want: true},
+		"Missing":                 {matcher: core.HeaderMatcher{Name: "Server"}, want: false},
+		"Lowercase name":          {matcher: core.HeaderMatcher{Name: "content-type", Value: "text/html"}, want: true},
+		"Non canonical key":       {matcher: core.HeaderMatcher{Name: "X-Custom", Value: "ok"}, want: true},
+		"Value not found":         {matcher: core.HeaderMatcher{Name: "Content-Type", Value: "json"}, want: false},
+		"Value with colon":        {matcher: core.ParseHeaderMatcher("Location: https://example.com"), want: true},
+		"Regex":                   {matcher: core.HeaderMatcher{Name: "Location", Regex: "^https://[^/]+/login$"}, want: true},
+		"Regex not matching":      {matcher: core.HeaderMatcher{Name: "Location", Regex: "^http://"}, want: false},
+		"Absent":                  {matcher: core.HeaderMatcher{Name: "Strict-Transport-Security", Absent: true}, want: true},
+		"Absent but present":      {matcher: core.HeaderMatcher{Name: "location", Absent: true}, want: false},
+		"Value and regex matched": {matcher: core.HeaderMatcher{Name: "Content-Type", Value: "text", Regex: "utf-8$"}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.matcher.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if have := tc.matcher.Match(header); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestParseHeaderMatcher(t *testing.T) {
+	var tests = map[string]struct {
+		header string
+		want   core.HeaderMatcher
+	}{
+		"Name and value": {header: "Server:Apache", want: core.HeaderMatcher{Name: "Server", Value: "Apache"}},
+		"Spaces":         {header: "Server: Apache ", want: core.HeaderMatcher{Name: "Server", Value: "Apache"}},
+		"Name only":      {header: "X-Powered-By", want: core.HeaderMatcher{Name: "X-Powered-By"}},
+		"Colon in value": {header: "Location:https://example.com:8443/", want: core.HeaderMatcher{Name: "Location", Value: "https://example.com:8443/"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.ParseHeaderMatcher(tc.header)
+			if !have.Equals(&tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestHeaderMatcherUnmarshal(t *testing.T) {
+	var tests = map[string]struct {
+		yaml string
+		want core.HeaderMatcher
+		err  bool
+	}{
+		"String":        {yaml: `"Server:Apache"`, want: core.HeaderMatcher{Name: "Server", Value: "Apache"}},
+		"Mapping":       {yaml: "{name: Server, value: Apache}", want: core.HeaderMatcher{Name: "Server", Value: "Apache"}},
+		"Regex":         {yaml: `{name: Location, regex: "^https://"}`, want: core.HeaderMatcher{Name: "Location", Regex: "^https://"}},
+		"Absent":        {yaml: "{name: X-Frame-Options, absent: true}", want: core.HeaderMatcher{Name: "X-Frame-Options", Absent: true}},
+		"Invalid regex": {yaml: `{name: Location, regex: "("}`, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var have core.HeaderMatcher
+			err := yaml.Unmarshal([]byte(tc.yaml), &have)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected an error, got: %v", have)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !have.Equals(&tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestHeaderMatcherValidate(t *testing.T) {
+	var tests = map[string]struct {
+		matcher core.HeaderMatcher
+		valid   bool
+	}{
+		"Name only":         {matcher: core.HeaderMatcher{Name: "Server"}, valid: true},
+		"Missing name":      {matcher: core.HeaderMatcher{Value: "Apache"}, valid: false},
+		"Absent with value": {matcher: core.HeaderMatcher{Name: "Server", Value: "Apache", Absent: true}, valid: false},
+		"Invalid regex":     {matcher: core.HeaderMatcher{Name: "Server", Regex: "("}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.matcher.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestNoHeadersKeyOnly(t *testing.T) {
+	matchers := core.Matchers{NoHeaders: []core.HeaderMatcher{{Name: "X-Powered-By"}}}
+	var tests = map[string]struct {
+		header http.Header
+		want   bool
+	}{
+		"Header absent":  {header: http.Header{}, want: true},
+		"Header present": {header: http.Header{"X-Powered-By": []string{"PHP"}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &internal.HTTPResponse{StatusCode: 200, Header: tc.header}
+			if have := matchers.Match(resp); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestMatchersValidateCompilesRegex(t *testing.T) {
+	resp := &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"Location": []string{"https://example.com/"}}}
+	matchers := core.Matchers{Headers: []core.HeaderMatcher{{Name: "Location", Regex: "^https://"}}}
+	if matchers.Match(resp) {
+		t.Errorf("expected a regex not compiled to match nothing")
+	}
+	if err := matchers.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matchers.Match(resp) {
+		t.Errorf("expected the regex compiled by Validate to match")
+	}
+}
@@ -0,0 +1,407 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"mime"
+	"strings"
+)
+
+// Matchers are the criteria a response is matched against, they can be set on a check or in a condition
+type Matchers struct {
+	StatusCode    StatusCodes      `yaml:"status_code,omitempty"`
+	NotStatusCode StatusCodes      `yaml:"not_status_code,omitempty"`
+	MustMatchOne  []string         `yaml:"match,omitempty"`
+	MustMatchAll  []string         `yaml:"all_match,omitempty"`
+	MustNotMatch  []string         `yaml:"no_match,omitempty"`
+	Hex           []string         `yaml:"hex,omitempty"`
+	FileType      string           `yaml:"file_type,omitempty"`
+	ContentType   []string         `yaml:"content_type,omitempty"`
+	Headers       []HeaderMatcher  `yaml:"headers,omitempty"`
+	NoHeaders     []HeaderMatcher  `yaml:"no_headers,omitempty"`
+	Cookies       []CookieMatcher  `yaml:"cookies,omitempty"`
+	TLS           *TLSMatcher      `yaml:"tls,omitempty"`
+	Redirect      *RedirectMatcher `yaml:"redirect,omitempty"`
+	// Truncated tells if the body must have been read partially or completely
+	Truncated *bool `yaml:"truncated,omitempty"`
+}
+
+// Condition is a boolean expression of matchers.
+// A condition matches when its own matchers, all of its All conditions, one of its Any conditions
+// and not its Not condition match, the parts left empty being ignored.
+type Condition struct {
+	Matchers `yaml:",inline"`
+	All      []*Condition `yaml:"all,omitempty"`
+	Any      []*Condition `yaml:"any,omitempty"`
+	Not      *Condition   `yaml:"not,omitempty"`
+}
+
+// Match analyses the HTTP Request
+// a match means that every matcher set has been met, an empty Matchers always matches
+func (m *Matchers) Match(resp *internal.HTTPResponse) bool {
+	if m.Truncated != nil && *m.Truncated != resp.Truncated {
+		return false
+	}
+	return m.matchResponse(resp) && m.matchBody(resp)
+}
+
+// matchResponse checks the matchers that don't depend on the body
+func (m *Matchers) matchResponse(resp *internal.HTTPResponse) bool {
+	// status code must match
+	if m.StatusCode != nil && !m.StatusCode.Contains(resp.StatusCode) {
+		return false
+	}
+
+	// status code must not match
+	if m.NotStatusCode.Contains(resp.StatusCode) {
+		return false
+	}
+
+	// media type must be one of these
+	if len(m.ContentType) > 0 && !matchContentType(headerValue(resp, "Content-Type"), m.ContentType) {
+		return false
+	}
+
+	// must contain all these headers
+	for _, header := range m.Headers {
+		if !header.Match(resp.Header) {
+			return false
+		}
+	}
+
+	// must not contain these headers, a header without value must be absent
+	for _, header := range m.NoHeaders {
+		if header.Match(resp.Header) {
+			return false
+		}
+	}
+
+	// must set all these cookies
+	if len(m.Cookies) > 0 {
+		cookies := resp.Cookies()
+		for _, cookie := range m.Cookies {
+			if !cookie.Match(cookies) {
+				return false
+			}
+		}
+	}
+
+	// the connection and certificate must match
+	if m.TLS != nil && !m.TLS.Match(resp.TLS, resp.URL) {
+		return false
+	}
+
+	// the redirect chain must match
+	if m.Redirect != nil && !m.Redirect.Match(resp) {
+		return false
+	}
+	return true
+}
+
+// matchBody checks the matchers searching the body, binary ones using the raw body
+func (m *Matchers) matchBody(resp *internal.HTTPResponse) bool {
+	body := resp.Body
+	// all element must be found
+	for _, match := range m.MustMatchAll {
+		if !strings.Contains(body, match) {
+			return false
+		}
+	}
+
+	// one element must be found
+	if len(m.MustMatchOne) > 0 {
+		found := false
+		for _, match := range m.MustMatchOne {
+			if strings.Contains(body, match) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// no element should match
+	if len(m.MustNotMatch) > 0 {
+		for _, match := range m.MustNotMatch {
+			if strings.Contains(body, match) {
+				return false
+			}
+		}
+	}
+
+	// all byte sequences must be found
+	for _, h := range m.Hex {
+		if sequence, err := decodeHex(h); err != nil || !strings.Contains(resp.Raw(), sequence) {
+			return false
+		}
+	}
+
+	// must be a file of this type
+	if m.FileType != "" && !matchFileType(resp.Raw(), m.FileType) {
+		return false
+	}
+	return true
+}
+
+// textStrings returns the strings searched in the body
+func (m *Matchers) textStrings() []string {
+	var strs []string
+	strs = append(strs, m.MustMatchAll...)
+	strs = append(strs, m.MustMatchOne...)
+	return append(strs, m.MustNotMatch...)
+}
+
+// rawStrings returns the byte sequences searched in the raw body
+func (m *Matchers) rawStrings() []string {
+	var strs []string
+	for _, h := range m.Hex {
+		if sequence, err := decodeHex(h); err == nil {
+			strs = append(strs, sequence)
+		}
+	}
+	return strs
+}
+
+// Validate returns an error if a matcher is malformed
+func (m *Matchers) Validate() error {
+	if err := m.StatusCode.Validate(); err != nil {
+		return err
+	}
+	if err := m.NotStatusCode.Validate(); err != nil {
+		return err
+	}
+	for _, h := range m.Hex {
+		if _, err := decodeHex(h); err != nil {
+			return err
+		}
+	}
+	if m.FileType != "" {
+		if _, found := fileTypes[strings.ToLower(m.FileType)]; !found {
+			return fmt.Errorf("Invalid file type : %s. Please use : %s", m.FileType, strings.Join(FileTypes(), ", "))
+		}
+	}
+	for _, contentType := range m.ContentType {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return fmt.Errorf("Invalid content type : %s", contentType)
+		}
+	}
+	// the matchers are validated in place, so that their regexes stay compiled
+	for _, headers := range [][]HeaderMatcher{m.Headers, m.NoHeaders} {
+		for i := range headers {
+			if err := headers[i].Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range m.Cookies {
+		if err := m.Cookies[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if m.TLS != nil {
+		if err := m.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+	if m.Redirect != nil {
+		return m.Redirect.Validate()
+	}
+	return nil
+}
+
+// Conditions describes the matchers, one matcher per element
+func (m *Matchers) Conditions() []string {
+	var conditions []string
+	if m.StatusCode != nil {
+		conditions = append(conditions, fmt.Sprintf("status_code: %s", m.StatusCode))
+	}
+	if m.NotStatusCode != nil {
+		conditions = append(conditions, fmt.Sprintf("not_status_code: %s", m.NotStatusCode))
+	}
+	if len(m.MustMatchOne) > 0 {
+		conditions = append(conditions, fmt.Sprintf("match: %s", quoteAll(m.MustMatchOne)))
+	}
+	if len(m.MustMatchAll) > 0 {
+		conditions = append(conditions, fmt.Sprintf("all_match: %s", quoteAll(m.MustMatchAll)))
+	}
+	if len(m.MustNotMatch) > 0 {
+		conditions = append(conditions, fmt.Sprintf("no_match: %s", quoteAll(m.MustNotMatch)))
+	}
+	if len(m.Hex) > 0 {
+		conditions = append(conditions, fmt.Sprintf("hex: %s", quoteAll(m.Hex)))
+	}
+	if m.FileType != "" {
+		conditions = append(conditions, fmt.Sprintf("file_type: %s", m.FileType))
+	}
+	if len(m.ContentType) > 0 {
+		conditions = append(conditions, fmt.Sprintf("content_type: %s", strings.Join(m.ContentType, ", ")))
+	}
+	if len(m.Headers) > 0 {
+		conditions = append(conditions, fmt.Sprintf("headers: %s", headerMatchersString(m.Headers)))
+	}
+	if len(m.NoHeaders) > 0 {
+		conditions = append(conditions, fmt.Sprintf("no_headers: %s", headerMatchersString(m.NoHeaders)))
+	}
+	if len(m.Cookies) > 0 {
+		conditions = append(conditions, fmt.Sprintf("cookies: %s", cookieMatchersString(m.Cookies)))
+	}
+	if m.TLS != nil {
+		conditions = append(conditions, fmt.Sprintf("tls: %s", m.TLS))
+	}
+	if m.Redirect != nil {
+		conditions = append(conditions, fmt.Sprintf("redirect: %s", m.Redirect))
+	}
+	if m.Truncated != nil {
+		conditions = append(conditions, fmt.Sprintf("truncated: %t", *m.Truncated))
+	}
+	return conditions
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (self *Matchers) Equals(matchers *Matchers) bool {
+	if !SliceStringEqual(self.MustMatchOne, matchers.MustMatchOne) {
+		return false
+	}
+	if !SliceStringEqual(self.MustMatchAll, matchers.MustMatchAll) {
+		return false
+	}
+	if !SliceStringEqual(self.MustNotMatch, matchers.MustNotMatch) {
+		return false
+	}
+	if !SliceStringEqual(self.Hex, matchers.Hex) || self.FileType != matchers.FileType {
+		return false
+	}
+	if !SliceStringEqual(self.ContentType, matchers.ContentType) {
+		return false
+	}
+	if !self.StatusCode.Equals(matchers.StatusCode) {
+		return false
+	}
+	if !self.NotStatusCode.Equals(matchers.NotStatusCode) {
+		return false
+	}
+	if !headerMatchersEqual(self.Headers, matchers.Headers) {
+		return false
+	}
+	if !headerMatchersEqual(self.NoHeaders, matchers.NoHeaders) {
+		return false
+	}
+	if !cookieMatchersEqual(self.Cookies, matchers.Cookies) {
+		return false
+	}
+	if !self.TLS.Equals(matchers.TLS) {
+		return false
+	}
+	if !self.Redirect.Equals(matchers.Redirect) {
+		return false
+	}
+	if (self.Truncated == nil) != (matchers.Truncated == nil) {
+		return false
+	}
+	if self.Truncated != nil && *self.Truncated != *matchers.Truncated {
+		return false
+	}
+	return true
+}
+
+// Match evaluates the condition against the HTTP response
+func (c *Condition) Match(resp *internal.HTTPResponse) bool {
+	if !c.Matchers.Match(resp) {
+		return false
+	}
+	for _, condition := range c.All {
+		if !condition.Match(resp) {
+			return false
+		}
+	}
+	if len(c.Any) > 0 {
+		found := false
+		for _, condition := range c.Any {
+			if condition.Match(resp) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if c.Not != nil && c.Not.Match(resp) {
+		return false
+	}
+	return true
+}
+
+// Validate returns an error if the condition or one of its sub-conditions is malformed or empty
+func (c *Condition) Validate() error {
+	if len(c.Matchers.Conditions()) == 0 && len(c.All) == 0 && len(c.Any) == 0 && c.Not == nil {
+		return fmt.Errorf("Empty condition, it should set matchers or all, any, not blocks")
+	}
+	if err := c.Matchers.Validate(); err != nil {
+		return err
+	}
+	for _, condition := range append(c.All, c.Any...) {
+		if err := condition.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Not != nil {
+		return c.Not.Validate()
+	}
+	return nil
+}
+
+// String describes the condition as a boolean expression
+func (c *Condition) String() string {
+	var parts []string
+	parts = append(parts, c.Matchers.Conditions()...)
+	if len(c.All) > 0 {
+		parts = append(parts, joinConditions(c.All, " and "))
+	}
+	if len(c.Any) > 0 {
+		parts = append(parts, joinConditions(c.Any, " or "))
+	}
+	if c.Not != nil {
+		parts = append(parts, fmt.Sprintf("not (%s)", c.Not))
+	}
+	return strings.Join(parts, " and ")
+}
+
+func joinConditions(conditions []*Condition, operator string) string {
+	s := make([]string, len(conditions))
+	for i, condition := range conditions {
+		s[i] = fmt.Sprintf("(%s)", condition)
+	}
+	return "(" + strings.Join(s, operator) + ")"
+}
+
+func (self *Condition) Equals(condition *Condition) bool {
+	if !self.Matchers.Equals(&condition.Matchers) {
+		return false
+	}
+	if len(self.All) != len(condition.All) || len(self.Any) != len(condition.Any) {
+		return false
+	}
+	for i := range self.All {
+		if !self.All[i].Equals(condition.All[i]) {
+			return false
+		}
+	}
+	for i := range self.Any {
+		if !self.Any[i].Equals(condition.Any[i]) {
+			return false
+		}
+	}
+	if (self.Not == nil) != (condition.Not == nil) {
+		return false
+	}
+	return self.Not == nil || self.Not.Equals(condition.Not)
+}
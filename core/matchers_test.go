@@ -0,0 +1,108 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestConditionMatch(t *testing.T) {
+	var check core.Check
+	err := yaml.Unmarshal([]byte(`
+name: expression
+status_code: 200
+condition:
+  all:
+    - any:
+        - match: ["A"]
+        - headers: ["X-Test:B"]
+    - not:
+        all_match: ["C", "D"]
+`), &check)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tests = map[string]struct {
+		resp *internal.HTTPResponse
+		want bool
+	}{
+		"Body A":                   {resp: &internal.HTTPResponse{StatusCode: 200, Body: "A"}, want: true},
+		"Header B":                 {resp: &internal.HTTPResponse{StatusCode: 200, Header: http.Header{"X-Test": []string{"B"}}}, want: true},
+		"Neither A nor B":          {resp: &internal.HTTPResponse{StatusCode: 200, Body: "E"}, want: false},
+		"A with C and D":           {resp: &internal.HTTPResponse{StatusCode: 200, Body: "A C D"}, want: false},
+		"A with C only":            {resp: &internal.HTTPResponse{StatusCode: 200, Body: "A C"}, want: true},
+		"Sugar fields still apply": {resp: &internal.HTTPResponse{StatusCode: 404, Body: "A"}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := check.Match(tc.resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestMatchersStatusCode(t *testing.T) {
+	matchers := &core.Matchers{
+		StatusCode:    core.StatusCodes{{Min: 200, Max: 299}},
+		NotStatusCode: core.NewStatusCodes(204),
+	}
+	var tests = map[string]struct {
+		code int
+		want bool
+	}{
+		"In class":     {code: 206, want: true},
+		"Excluded":     {code: 204, want: false},
+		"Out of class": {code: 302, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := matchers.Match(&internal.HTTPResponse{StatusCode: tc.code})
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	var tests = map[string]struct {
+		condition *core.Condition
+		valid     bool
+	}{
+		"Leaf":           {condition: &core.Condition{Matchers: core.Matchers{MustMatchOne: []string{"A"}}}, valid: true},
+		"Empty":          {condition: &core.Condition{}, valid: false},
+		"Empty not":      {condition: &core.Condition{Not: &core.Condition{}}, valid: false},
+		"Invalid header": {condition: &core.Condition{Any: []*core.Condition{{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Value: "NoName"}}}}}}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.condition.Validate()
+			if (err == nil) != tc.valid {
+				t.Errorf("expected valid: %v, got: %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	condition := &core.Condition{
+		Any: []*core.Condition{
+			{Matchers: core.Matchers{MustMatchOne: []string{"A"}}},
+			{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Name: "X-Test", Value: "B"}}}},
+		},
+		Not: &core.Condition{Matchers: core.Matchers{MustMatchAll: []string{"C", "D"}}},
+	}
+	want := `((match: "A") or (headers: "X-Test:B")) and not (all_match: "C", "D")`
+	if have := condition.String(); have != want {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+}
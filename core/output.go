@@ -2,9 +2,31 @@ package core
 
 // Output structure for each findings
 type Output struct {
-	URL         string `json:"url"`
-	Endpoint    string `json:"endpoint"`
-	Name        string `json:"checkName"`
-	Severity    string `json:"severity"`
-	Remediation string `json:"remediation"`
+	URL         string   `json:"url"`
+	Endpoint    string   `json:"endpoint"`
+	Name        string   `json:"checkName"`
+	Severity    string   `json:"severity"`
+	Remediation string   `json:"remediation"`
+	ID          string   `json:"id"`
+	Tags        []string `json:"tags,omitempty"`
+	References  []string `json:"references,omitempty"`
+	CWE         []string `json:"cwe,omitempty"`
+	CVE         []string `json:"cve,omitempty"`
+	// Status is new, present or resolved when the scan is compared to a baseline
+	Status string `json:"status,omitempty"`
+	// Suppression is suppressed when the finding is an accepted risk, expired when its suppression has expired
+	Suppression   string `json:"suppression,omitempty"`
+	Justification string `json:"justification,omitempty"`
+	// Incomplete is set when the finding comes from a scan stopped before running every check
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// MarkIncomplete returns the outputs of a scan stopped before running every check, marked as incomplete
+func MarkIncomplete(outputs []Output) []Output {
+	marked := make([]Output, len(outputs))
+	for i, output := range outputs {
+		output.Incomplete = true
+		marked[i] = output
+	}
+	return marked
 }
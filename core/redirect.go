@@ -0,0 +1,125 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"net/url"
+	"strings"
+)
+
+// RedirectMatcher is a condition on the redirect chain of the response, every field set having to be met.
+// With follow_redirects the chain holds every redirect followed, without it only the redirect response itself.
+type RedirectMatcher struct {
+	// Location is a substring of the URL one of the redirects leads to
+	Location string `yaml:"location,omitempty"`
+	// StatusCode matches if one of the redirects returned one of these codes
+	StatusCode StatusCodes `yaml:"status_code,omitempty"`
+	// External matches if one of the redirects leads to another host than the requested one
+	External bool `yaml:"external,omitempty"`
+	// ToHTTPS tells if the chain ends on an HTTPS URL, the URL of the response counting when there is no redirect
+	ToHTTPS *bool `yaml:"to_https,omitempty"`
+}
+
+// Match returns true if the redirect chain of the response satisfies every field of the matcher
+func (m *RedirectMatcher) Match(resp *internal.HTTPResponse) bool {
+	if m.Location != "" && !m.matchLocation(resp.Redirects) {
+		return false
+	}
+	if m.StatusCode != nil && !m.matchStatusCode(resp.Redirects) {
+		return false
+	}
+	if m.External && !redirectsExternally(resp) {
+		return false
+	}
+	if m.ToHTTPS != nil && endsOnHTTPS(resp) != *m.ToHTTPS {
+		return false
+	}
+	return true
+}
+
+func (m *RedirectMatcher) matchLocation(redirects []internal.Redirect) bool {
+	for _, redirect := range redirects {
+		if strings.Contains(redirect.Location, m.Location) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RedirectMatcher) matchStatusCode(redirects []internal.Redirect) bool {
+	for _, redirect := range redirects {
+		if m.StatusCode.Contains(redirect.StatusCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectsExternally returns true if a redirect leads to another host than the first URL of the chain
+func redirectsExternally(resp *internal.HTTPResponse) bool {
+	if len(resp.Redirects) == 0 {
+		return false
+	}
+	origin, err := url.Parse(resp.Redirects[0].URL)
+	if err != nil {
+		return false
+	}
+	for _, redirect := range resp.Redirects {
+		location, err := url.Parse(redirect.Location)
+		if err == nil && location.Host != "" && !strings.EqualFold(location.Hostname(), origin.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsOnHTTPS(resp *internal.HTTPResponse) bool {
+	last := resp.URL
+	if len(resp.Redirects) > 0 {
+		last = resp.Redirects[len(resp.Redirects)-1].Location
+	}
+	return strings.HasPrefix(strings.ToLower(last), "https://")
+}
+
+// Validate returns an error if the matcher is empty or has invalid status codes
+func (m *RedirectMatcher) Validate() error {
+	if len(m.Conditions()) == 0 {
+		return fmt.Errorf("Empty redirect condition, it should set at least one field")
+	}
+	return m.StatusCode.Validate()
+}
+
+// Conditions describes the fields of the matcher, one field per element
+func (m *RedirectMatcher) Conditions() []string {
+	var conditions []string
+	if m.Location != "" {
+		conditions = append(conditions, fmt.Sprintf("location: %q", m.Location))
+	}
+	if m.StatusCode != nil {
+		conditions = append(conditions, fmt.Sprintf("status_code: %s", m.StatusCode))
+	}
+	if m.External {
+		conditions = append(conditions, "external")
+	}
+	if m.ToHTTPS != nil {
+		conditions = append(conditions, fmt.Sprintf("to_https: %t", *m.ToHTTPS))
+	}
+	return conditions
+}
+
+func (m *RedirectMatcher) String() string {
+	return strings.Join(m.Conditions(), ", ")
+}
+
+func (self *RedirectMatcher) Equals(m *RedirectMatcher) bool {
+	if self == nil || m == nil {
+		return self == m
+	}
+	if (self.ToHTTPS == nil) != (m.ToHTTPS == nil) {
+		return false
+	}
+	if self.ToHTTPS != nil && *self.ToHTTPS != *m.ToHTTPS {
+		return false
+	}
+	return self.Location == m.Location && self.StatusCode.Equals(m.StatusCode) && self.External == m.External
+}
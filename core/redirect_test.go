@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"testing"
+)
+
+func TestRedirectMatcherMatch(t *testing.T) {
+	yes, no := true, false
+	toLogin := &internal.HTTPResponse{
+		StatusCode: 200,
+		URL:        "https://example.com/login",
+		Redirects: []internal.Redirect{
+			{URL: "http://example.com/admin", StatusCode: 301, Location: "https://example.com/admin"},
+			{URL: "https://example.com/admin", StatusCode: 302, Location: "https://example.com/login"},
+		},
+	}
+	external := &internal.HTTPResponse{
+		StatusCode: 302,
+		URL:        "http://example.com/?next=https://evil.org",
+		Redirects: []internal.Redirect{
+			{URL: "http://example.com/?next=https://evil.org", StatusCode: 302, Location: "https://evil.org"},
+		},
+	}
+	plain := &internal.HTTPResponse{StatusCode: 200, URL: "http://example.com/"}
+	var tests = map[string]struct {
+		matcher core.RedirectMatcher
+		resp    *internal.HTTPResponse
+		want    bool
+	}{
+		"Redirects to login":         {matcher: core.RedirectMatcher{Location: "/login"}, resp: toLogin, want: true},
+		"No redirect":                {matcher: core.RedirectMatcher{Location: "/login"}, resp: plain, want: false},
+		"Redirect status code":       {matcher: core.RedirectMatcher{StatusCode: core.NewStatusCodes(301)}, resp: toLogin, want: true},
+		"Other status code":          {matcher: core.RedirectMatcher{StatusCode: core.NewStatusCodes(307, 308)}, resp: toLogin, want: false},
+		"Same host":                  {matcher: core.RedirectMatcher{External: true}, resp: toLogin, want: false},
+		"External host":              {matcher: core.RedirectMatcher{External: true}, resp: external, want: true},
+		"Upgraded to HTTPS":          {matcher: core.RedirectMatcher{ToHTTPS: &no}, resp: toLogin, want: false},
+		"HTTP without redirect":      {matcher: core.RedirectMatcher{ToHTTPS: &no}, resp: plain, want: true},
+		"Ends on HTTPS":              {matcher: core.RedirectMatcher{ToHTTPS: &yes, Location: "/login"}, resp: toLogin, want: true},
+		"All fields must be met":     {matcher: core.RedirectMatcher{External: true, Location: "/login"}, resp: toLogin, want: false},
+		"Unfollowed redirect counts": {matcher: core.RedirectMatcher{Location: "evil.org", ToHTTPS: &yes}, resp: external, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := tc.matcher.Match(tc.resp); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRedirectMatcherValidate(t *testing.T) {
+	var tests = map[string]struct {
+		matcher core.RedirectMatcher
+		valid   bool
+	}{
+		"Location":            {matcher: core.RedirectMatcher{Location: "/login"}, valid: true},
+		"Empty":               {matcher: core.RedirectMatcher{}, valid: false},
+		"Invalid status code": {matcher: core.RedirectMatcher{StatusCode: core.NewStatusCodes(42)}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.matcher.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gochopchop/internal"
+	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -24,40 +27,70 @@ type IFetcher interface {
 	Fetch(url string) (*internal.HTTPResponse, error)
 }
 
+// IStreamFetcher is implemented by the fetchers able to stop reading the body once done returns true,
+// the request is aborted when ctx is done.
+type IStreamFetcher interface {
+	FetchUntil(ctx context.Context, url string, done func(resp *internal.HTTPResponse) bool) (*internal.HTTPResponse, error)
+}
+
 type IScanner interface {
 	Scan(urls []string) ([]Output, error)
 }
 
+// Scanner runs the checks of the signatures on URLs. It keeps nothing from one scan to the next and only reads
+// its fields and the signatures while scanning, so one scanner can run concurrent scans.
 type Scanner struct {
 	Signatures        *Signatures
 	Fetcher           IFetcher
 	NoRedirectFetcher IFetcher
 	// Two fetchers are needed because we can't use the same http client to follow redirects
-	safeData *SafeData
-	Threads  int
+	Threads int
+	// HeaderAudit checks the security headers of every response, nil disabling it
+	HeaderAudit *HeaderAudit
+	// Soft404 is the soft-404 mode, what happens to findings whose response is the catch-all page of the target
+	Soft404 string
+	// State records the progress of the scan when set, the jobs it holds as completed being skipped.
+	// It belongs to a single scan, concurrent scans setting their own on a copy of the scanner.
+	State *ScanState
+	// TargetBudget is the time given to each target, its remaining plugins being skipped once over, 0 for no limit
+	TargetBudget time.Duration
 }
 
 // NewScanner returns a pointer to a initialized Scanner
 func NewScanner(fetcher IFetcher, noRedirectFetcher IFetcher, signatures *Signatures, threads int) *Scanner {
-	safeData := &SafeData{out: make([]Output, 0)}
 	return &Scanner{
 		Signatures:        signatures,
 		Fetcher:           fetcher,
 		NoRedirectFetcher: noRedirectFetcher,
-		safeData:          safeData,
 		Threads:           threads,
+		HeaderAudit:       &HeaderAudit{Rules: DefaultAuditRules()},
+		Soft404:           Soft404Off,
 	}
 }
 
 type workerJob struct {
+	target   string
 	url      string
 	endpoint string
 	plugin   *Plugin
+	// ctx is done once the scan is cancelled or the time budget of the target is over
+	ctx context.Context
 }
 
 func (s Scanner) Scan(ctx context.Context, urls []string) ([]Output, error) {
 	wg := new(sync.WaitGroup)
 	jobs := make(chan workerJob)
+	// the findings are scoped to the call, so that a scanner can run several scans
+	results := &SafeData{out: make([]Output, 0)}
+	findings := &auditFindings{reported: make(map[string]bool)}
+	baselines := &soft404Baselines{baselines: make(map[string]*soft404Baseline)}
+	budgets := &targetBudgets{budget: s.TargetBudget, ctxs: make(map[string]context.Context)}
+	if s.State != nil {
+		// the header audit findings of a resumed scan were already reported
+		for _, output := range s.State.Outputs() {
+			findings.reported[strings.TrimSuffix(output.URL, output.Endpoint)+" "+output.ID] = true
+		}
+	}
 
 	for i := 0; i < s.Threads; i++ {
 		wg.Add(1)
@@ -71,56 +104,91 @@ func (s Scanner) Scan(ctx context.Context, urls []string) ([]Output, error) {
 					if !ok { // no more jobs
 						return
 					}
-					resp, err := s.fetch(job.url, job.plugin.FollowRedirects)
+					if job.ctx.Err() != nil {
+						budgets.skip(job)
+						break
+					}
+					echoes := endpointEchoes(job.endpoint)
+					baseline := s.soft404Baseline(baselines, job)
+					resp, err := s.fetch(job.ctx, job.url, job.plugin, baseline, echoes)
+					if err != nil && job.ctx.Err() != nil {
+						budgets.skip(job)
+						break
+					}
+					checks := job.plugin.Checks
 					if err != nil {
 						log.Error(err)
-						break
+						var certErr *internal.CertificateError
+						if !errors.As(err, &certErr) {
+							break
+						}
+						// the certificate of the server is still checked when it makes the request fail
+						resp, checks = &internal.HTTPResponse{URL: certErr.URL, TLS: certErr.TLS}, tlsChecks(checks)
+						if len(checks) == 0 {
+							break
+						}
+					}
+					soft404 := err == nil && baseline != nil && baseline.Match(NewFingerprint(resp, echoes...))
+					jobOutputs := &SafeData{}
+					if s.HeaderAudit != nil && err == nil {
+						for _, check := range s.HeaderAudit.Audit(job.url, resp) {
+							if findings.report(job.target, check) {
+								jobOutputs.Add(newOutput(job, check))
+							}
+						}
 					}
 					swg := new(sync.WaitGroup)
-					for _, check := range job.plugin.Checks {
+					for _, check := range checks {
 						swg.Add(1)
 						go func(check *Check) {
 							defer swg.Done()
 							select {
-							case <-ctx.Done():
+							case <-job.ctx.Done():
 								return
 							default:
-								if check.Match(resp) {
-									o := Output{
-										URL:         job.url,
-										Name:        check.Name,
-										Endpoint:    job.endpoint,
-										Severity:    check.Severity,
-										Remediation: check.Remediation,
-									}
-									s.safeData.Add(o)
+								if !check.Match(resp) {
+									return
+								}
+								output := newOutput(job, check)
+								if !soft404 || s.soft404Output(&output) {
+									jobOutputs.Add(output)
 								}
 							}
 						}(check)
 					}
 					swg.Wait()
+					if job.ctx.Err() != nil {
+						budgets.skip(job)
+					} else if s.State != nil {
+						s.State.complete(jobKey(job.url, job.plugin), jobOutputs.out)
+					}
+					for _, output := range jobOutputs.out {
+						results.Add(output)
+					}
 				}
 			}
 		}()
 	}
 
+feed:
 	for _, url := range urls {
 		for _, plugin := range s.Signatures.Plugins {
-			if plugin.Endpoint != "" {
-				plugin.Endpoints = []string{plugin.Endpoint}
-			}
-			for _, e := range plugin.Endpoints {
-				endpoint := e
-				if plugin.QueryString != "" {
-					endpoint = fmt.Sprintf("%s?%s", endpoint, plugin.QueryString)
-				}
+			for _, endpoint := range plugin.requestEndpoints() {
 				fullURL := fmt.Sprintf("%s%s", url, endpoint)
+				if s.State != nil && s.State.done(jobKey(fullURL, plugin)) {
+					log.Debug("Already tested : ", fullURL)
+					continue
+				}
 				log.Info("Testing url : ", fullURL)
 
-				w := workerJob{url: fullURL, endpoint: endpoint, plugin: plugin}
+				w := workerJob{target: url, url: fullURL, endpoint: endpoint, plugin: plugin, ctx: budgets.context(ctx, url)}
+				if w.ctx.Err() != nil && ctx.Err() == nil {
+					budgets.skip(w)
+					continue
+				}
 				select {
 				case <-ctx.Done():
-					break
+					break feed
 				case jobs <- w:
 				}
 			}
@@ -130,20 +198,57 @@ func (s Scanner) Scan(ctx context.Context, urls []string) ([]Output, error) {
 	close(jobs)
 	wg.Wait()
 
-	return s.safeData.out, nil
+	// the findings gathered before a cancellation are returned along with its cause
+	return results.out, budgets.err(ctx)
+}
+
+func newOutput(job workerJob, check *Check) Output {
+	return Output{
+		URL:         job.url,
+		Name:        check.Name,
+		Endpoint:    job.endpoint,
+		Severity:    check.Severity,
+		Remediation: check.Remediation,
+		ID:          check.ID,
+		Tags:        check.Tags,
+		References:  check.References,
+		CWE:         check.CWE,
+		CVE:         check.CVE,
+	}
+}
+
+// tlsChecks returns the checks having only tls conditions
+func tlsChecks(checks []*Check) []*Check {
+	var selected []*Check
+	for _, check := range checks {
+		if check.tlsOnly() {
+			selected = append(selected, check)
+		}
+	}
+	return selected
 }
 
-func (s Scanner) fetch(url string, followRedirects bool) (*internal.HTTPResponse, error) {
-	var httpResponse *internal.HTTPResponse
-	var err error
+func (s Scanner) fetcher(plugin *Plugin) IFetcher {
+	if plugin.FollowRedirects {
+		return s.Fetcher
+	}
+	return s.NoRedirectFetcher
+}
 
-	if !followRedirects {
-		httpResponse, err = s.NoRedirectFetcher.Fetch(url)
-	} else {
-		httpResponse, err = s.Fetcher.Fetch(url)
+// fetch fetches the url for the plugin, reading the body while the checks need it or while it may be
+// the soft-404 baseline page
+func (s Scanner) fetch(ctx context.Context, url string, plugin *Plugin, baseline *Fingerprint, echoes []string) (*internal.HTTPResponse, error) {
+	fetcher := s.fetcher(plugin)
+	streamFetcher, ok := fetcher.(IStreamFetcher)
+	if !ok {
+		return fetcher.Fetch(url)
 	}
-	if err != nil {
-		return nil, err
+	done := plugin.bodyDecider()
+	if done != nil && baseline != nil {
+		decided := done
+		done = func(resp *internal.HTTPResponse) bool {
+			return decided(resp) && !baseline.mayMatch(resp, echoes)
+		}
 	}
-	return httpResponse, nil
+	return streamFetcher.FetchUntil(ctx, url, done)
 }
@@ -2,8 +2,10 @@ package core_test
 
 import (
 	"context"
+	"fmt"
 	"gochopchop/core"
 	"gochopchop/mock"
+	"sync"
 	"testing"
 	"time"
 )
@@ -50,3 +52,69 @@ func TestScan(t *testing.T) {
 		})
 	}
 }
+
+func TestScanCancelledReturnsPartialResults(t *testing.T) {
+	var plugins []*core.Plugin
+	for i := 0; i < 20; i++ {
+		check := &core.Check{ID: fmt.Sprintf("check-%d", i), Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}
+		plugins = append(plugins, &core.Plugin{Endpoint: fmt.Sprintf("/%d", i), Checks: []*core.Check{check}})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200), mock.WithCancel(5, cancel))
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 1)
+
+	output, err := scanner.Scan(ctx, []string{"http://site"})
+	if err != context.Canceled {
+		t.Errorf("expected error: %v, got: %v", context.Canceled, err)
+	}
+	if len(output) < 4 || len(output) > 5 {
+		t.Errorf("expected the findings gathered before the cancellation, got: %v", output)
+	}
+	if fetcher.Requests() > 6 {
+		t.Errorf("expected the scan to stop after the cancellation, got %d requests", fetcher.Requests())
+	}
+}
+
+func TestScanTwice(t *testing.T) {
+	first, _ := mock.FakeScanner.Scan(context.Background(), []string{"http://problems"})
+	second, _ := mock.FakeScanner.Scan(context.Background(), []string{"http://problems"})
+	if len(first) == 0 || len(second) != len(first) {
+		t.Errorf("expected each scan to return only its own findings, got %d then %d", len(first), len(second))
+	}
+}
+
+func TestScanConcurrent(t *testing.T) {
+	signatures, err := core.ParseSignatures([]byte("plugins:\n- endpoints: [/a, /b]\n  query_string: x=1\n  checks:\n" +
+		"  - {name: Found, description: d, remediation: r, severity: Low, status_code: 200}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200))
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 2)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			output, err := scanner.Scan(context.Background(), []string{target})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(output) != 2 {
+				t.Errorf("expected 2 findings on %s, got: %v", target, output)
+			}
+			for _, o := range output {
+				if o.URL != target+o.Endpoint || (o.Endpoint != "/a?x=1" && o.Endpoint != "/b?x=1") {
+					t.Errorf("unexpected finding on %s: %v", target, o)
+				}
+			}
+		}(fmt.Sprintf("http://site-%d", i))
+	}
+	wg.Wait()
+	if len(signatures.Plugins[0].Endpoints) != 2 {
+		t.Errorf("expected the signatures to be left untouched, got: %v", signatures.Plugins[0])
+	}
+}
@@ -1,8 +1,12 @@
 package core
 
 import (
+	"fmt"
 	"gochopchop/internal"
+	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 // Signature struct to load the plugins/rules from the YAML file
@@ -11,25 +15,26 @@ type Signatures struct {
 }
 
 type Plugin struct {
-	Endpoints       []string `yaml:"endpoints"`
-	Endpoint        string   `yaml:"endpoint"`
-	QueryString     string   `yaml:"query_string"`
-	Checks          []*Check `yaml:"checks"`
-	FollowRedirects bool     `yaml:"follow_redirects"`
+	Endpoints       []string `yaml:"endpoints,omitempty"`
+	Endpoint        string   `yaml:"endpoint,omitempty"`
+	QueryString     string   `yaml:"query_string,omitempty"`
+	Checks          []*Check `yaml:"checks,omitempty"`
+	FollowRedirects bool     `yaml:"follow_redirects,omitempty"`
 }
 
 // Check Signature
 type Check struct {
-	MustMatchOne []string `yaml:"match"`
-	MustMatchAll []string `yaml:"all_match"`
-	MustNotMatch []string `yaml:"no_match"`
-	StatusCode   *int32   `yaml:"status_code"`
-	Name         string   `yaml:"name"`
-	Remediation  string   `yaml:"remediation"`
-	Severity     string   `yaml:"severity"`
-	Description  string   `yaml:"description"`
-	Headers      []string `yaml:"headers"`
-	NoHeaders    []string `yaml:"no_headers"`
+	Name        string   `yaml:"name,omitempty"`
+	ID          string   `yaml:"id,omitempty"`
+	Severity    string   `yaml:"severity,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Remediation string   `yaml:"remediation,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	References  []string `yaml:"references,omitempty"`
+	CWE         []string `yaml:"cwe,omitempty"`
+	CVE         []string `yaml:"cve,omitempty"`
+	Matchers    `yaml:",inline"`
+	Condition   *Condition `yaml:"condition,omitempty"`
 }
 
 // NewSignatures returns a new initialized Signatures
@@ -37,124 +42,174 @@ func NewSignatures() *Signatures {
 	return &Signatures{}
 }
 
-func (s *Signatures) FilterBySeverity(severity string) {
-	filteredPlugins := s.Plugins[:0]
-	for _, plugin := range s.Plugins {
-		filteredChecks := plugin.Checks[:0]
+// ParseSignatures reads the signatures of a YAML signature file and validates them,
+// the checks without id getting the one derived from their name
+func ParseSignatures(data []byte) (*Signatures, error) {
+	signatures := NewSignatures()
+	if err := yaml.Unmarshal(data, signatures); err != nil {
+		return nil, err
+	}
+	for _, plugin := range signatures.Plugins {
 		for _, check := range plugin.Checks {
-			if check.Severity == severity {
-				filteredChecks = append(filteredChecks, check)
+			if check.ID == "" {
+				check.ID = DefaultCheckID(check.Name)
 			}
 		}
-		if len(filteredChecks) > 0 {
-			plugin.Checks = filteredChecks
-			filteredPlugins = append(filteredPlugins, plugin)
-		}
 	}
-	s.Plugins = filteredPlugins
+	if err := signatures.Validate(); err != nil {
+		return nil, err
+	}
+	return signatures, nil
 }
 
-func (s *Signatures) FilterByNames(names []string) {
-	filteredPlugins := s.Plugins[:0]
+// Validate returns an error if a plugin or a check is malformed or if two checks share an id
+func (s *Signatures) Validate() error {
+	ids := make(map[string]string)
+
 	for _, plugin := range s.Plugins {
-		filteredChecks := plugin.Checks[:0]
+		if plugin.Endpoint != "" && len(plugin.Endpoints) > 0 {
+			return fmt.Errorf("URI and URIs can't be set at the same time in plugin checks. Stopping execution")
+		}
+		if len(plugin.GetEndpoints()) == 0 {
+			return fmt.Errorf("Missing endpoint or endpoints field in plugin checks. Stopping execution")
+		}
 		for _, check := range plugin.Checks {
-			for _, name := range names {
-				if strings.Contains(strings.ToLower(check.Name), strings.ToLower(name)) {
-					filteredChecks = append(filteredChecks, check)
-					break
-				}
+			if name, found := ids[check.ID]; found {
+				return fmt.Errorf("Duplicate id %s in %s and %s plugin checks. Stopping execution", check.ID, name, check.Name)
+			}
+			ids[check.ID] = check.Name
+			if check.Description == "" {
+				return fmt.Errorf("Missing or empty description field in %s plugin checks. Stopping execution", check.Name)
+			}
+			if check.Remediation == "" {
+				return fmt.Errorf("Missing or empty remediation field in %s plugin checks. Stopping execution", check.Name)
+			}
+			if check.Severity == "" {
+				return fmt.Errorf("Missing severity field in %s plugin checks. Stopping execution", check.Name)
+			}
+			if !ValidSeverity(check.Severity) {
+				return fmt.Errorf("Invalid severity : %s. Please use : %s", check.Severity, SeveritiesAsString())
+			}
+			if err := check.Validate(); err != nil {
+				return fmt.Errorf("%v in %s plugin checks. Stopping execution", err, check.Name)
 			}
-		}
-		if len(filteredChecks) > 0 {
-			plugin.Checks = filteredChecks
-			filteredPlugins = append(filteredPlugins, plugin)
 		}
 	}
-	s.Plugins = filteredPlugins
+	return nil
 }
 
-//Match analyses the HTTP Request
-// a match means that one of the criteria has been met
-func (check *Check) Match(resp *internal.HTTPResponse) bool {
-	// status code must match
-	if check.StatusCode != nil {
-		if int32(resp.StatusCode) != *check.StatusCode {
-			return false
-		}
+// GetEndpoints returns the endpoints of the plugin, whether it uses endpoint or endpoints
+func (plugin *Plugin) GetEndpoints() []string {
+	if plugin.Endpoint != "" {
+		return []string{plugin.Endpoint}
 	}
+	return plugin.Endpoints
+}
 
-	// all element must be found
-	for _, match := range check.MustMatchAll {
-		if !strings.Contains(resp.Body, match) {
-			return false
+// requestEndpoints returns the endpoints the plugin requests, with its query string.
+// They are derived without modifying the plugin, so that concurrent scans can share it.
+func (plugin *Plugin) requestEndpoints() []string {
+	endpoints := plugin.GetEndpoints()
+	requests := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		if plugin.QueryString != "" {
+			endpoint = fmt.Sprintf("%s?%s", endpoint, plugin.QueryString)
 		}
+		requests[i] = endpoint
 	}
+	return requests
+}
 
-	// one element must be found
-	if len(check.MustMatchOne) > 0 {
-		found := false
-		for _, match := range check.MustMatchOne {
-			if strings.Contains(resp.Body, match) {
-				found = true
+// Conditions describes what the check matches on, one condition per element
+func (check *Check) Conditions() []string {
+	conditions := check.Matchers.Conditions()
+	if check.Condition != nil {
+		conditions = append(conditions, fmt.Sprintf("condition: %s", check.Condition))
+	}
+	return conditions
+}
+
+var nonAlphanumeric = regexp.MustCompile("[^a-z0-9]+")
+
+// DefaultCheckID derives a check id from its name, used when a signature doesn't set one
+func DefaultCheckID(name string) string {
+	return strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// filterChecks returns the signatures keeping only the checks for which keep returns true, without the plugins
+// left without checks. The plugins are copied so that the signatures filtered are left untouched.
+func (s *Signatures) filterChecks(keep func(check *Check) bool) *Signatures {
+	filtered := NewSignatures()
+	for _, plugin := range s.Plugins {
+		var checks []*Check
+		for _, check := range plugin.Checks {
+			if keep(check) {
+				checks = append(checks, check)
 			}
 		}
-		if !found {
-			return false
+		if len(checks) > 0 {
+			p := *plugin
+			p.Checks = checks
+			filtered.Plugins = append(filtered.Plugins, &p)
 		}
 	}
+	return filtered
+}
 
-	// no element should match
-	if len(check.MustNotMatch) > 0 {
-		for _, match := range check.MustNotMatch {
-			if strings.Contains(resp.Body, match) {
-				return false
-			}
+// AddPlugin appends the plugin to the signatures, its checks are merged into an existing plugin
+// requesting the same endpoints the same way so that they share the HTTP requests
+func (s *Signatures) AddPlugin(plugin *Plugin) {
+	for _, p := range s.Plugins {
+		if p.QueryString == plugin.QueryString && p.FollowRedirects == plugin.FollowRedirects &&
+			SliceStringEqual(p.GetEndpoints(), plugin.GetEndpoints()) {
+			p.Checks = append(p.Checks, plugin.Checks...)
+			return
 		}
 	}
+	s.Plugins = append(s.Plugins, plugin)
+}
 
-	// must contain all these headers
-	for _, header := range check.Headers {
-		pHeaders := strings.Split(header, ":")
-		pHeadersKey := pHeaders[0]
-		pHeadersValue := pHeaders[1]
-		if respHeaderValues, kFound := resp.Header[pHeadersKey]; kFound {
-			vFound := false
-			for _, respHeaderValue := range respHeaderValues {
-				if strings.Contains(respHeaderValue, pHeadersValue) {
-					vFound = true
-					break
-				}
-			}
-			if !vFound {
-				return false
+// Filter keeps only the checks selected by the filter
+func (s *Signatures) Filter(filter *Filter) {
+	s.Plugins = s.filterChecks(filter.Match).Plugins
+}
+
+// Filtered returns the signatures keeping only the checks selected by the filter, leaving s untouched,
+// to run different checks of the same signatures in concurrent scans
+func (s *Signatures) Filtered(filter *Filter) *Signatures {
+	return s.filterChecks(filter.Match)
+}
+
+// HasAnyTag returns true if the check is tagged with one of tags, tags are compared case-insensitively
+func (check *Check) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, checkTag := range check.Tags {
+			if strings.EqualFold(tag, checkTag) {
+				return true
 			}
-		} else {
-			return false
 		}
 	}
+	return false
+}
 
-	// must not contain these headers
-	for _, header := range check.NoHeaders {
-		pNoHeaders := strings.Split(header, ":")
-		pNoHeadersKey := pNoHeaders[0]
-		if respHeaderValues, kFound := resp.Header[pNoHeadersKey]; kFound {
-			if len(pNoHeaders) > 1 {
-				pHeadersValue := pNoHeaders[1]
-				vFound := false
-				for _, respHeaderValue := range respHeaderValues {
-					if strings.Contains(respHeaderValue, pHeadersValue) {
-						vFound = true
-						break
-					}
-				}
-				if vFound {
-					return false
-				}
-			}
-		}
+// Match analyses the HTTP Request
+// a match means that the matchers of the check and its condition are all met
+func (check *Check) Match(resp *internal.HTTPResponse) bool {
+	if !check.Matchers.Match(resp) {
+		return false
 	}
-	return true
+	return check.Condition == nil || check.Condition.Match(resp)
+}
+
+// Validate returns an error if the matchers of the check are malformed
+func (check *Check) Validate() error {
+	if err := check.Matchers.Validate(); err != nil {
+		return err
+	}
+	if check.Condition != nil {
+		return check.Condition.Validate()
+	}
+	return nil
 }
 
 func (self *Signatures) Equals(signatures *Signatures) bool {
@@ -205,36 +260,40 @@ func (self *Plugin) Equals(plugin *Plugin) bool {
 }
 
 func (self *Check) Equals(check *Check) bool {
-	if !SliceStringEqual(self.MustMatchOne, check.MustMatchOne) {
+	if !self.Matchers.Equals(&check.Matchers) {
 		return false
 	}
-	if !SliceStringEqual(self.MustMatchAll, check.MustMatchAll) {
+	if (self.Condition == nil) != (check.Condition == nil) {
 		return false
 	}
-	if !SliceStringEqual(self.MustNotMatch, check.MustNotMatch) {
+	if self.Condition != nil && !self.Condition.Equals(check.Condition) {
 		return false
 	}
-	if self.StatusCode != nil && check.StatusCode != nil {
-		if *self.StatusCode != *check.StatusCode {
-			return false
-		}
+	if self.ID != check.ID {
+		return false
 	}
-	if self.Name != check.Name {
+	if !SliceStringEqual(self.Tags, check.Tags) {
 		return false
 	}
-	if self.Remediation != check.Remediation {
+	if !SliceStringEqual(self.References, check.References) {
 		return false
 	}
-	if self.Severity != check.Severity {
+	if !SliceStringEqual(self.CWE, check.CWE) {
 		return false
 	}
-	if self.Description != check.Description {
+	if !SliceStringEqual(self.CVE, check.CVE) {
 		return false
 	}
-	if !SliceStringEqual(self.Headers, check.Headers) {
+	if self.Name != check.Name {
 		return false
 	}
-	if !SliceStringEqual(self.NoHeaders, check.NoHeaders) {
+	if self.Remediation != check.Remediation {
+		return false
+	}
+	if self.Severity != check.Severity {
+		return false
+	}
+	if self.Description != check.Description {
 		return false
 	}
 	return true
@@ -251,3 +310,12 @@ func SliceStringEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func containsString(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -6,52 +6,114 @@ import (
 	"testing"
 )
 
-func TestFilterBySeverity(t *testing.T) {
+func TestFilter(t *testing.T) {
 	var tests = map[string]struct {
-		have     *core.Signatures
-		want     *core.Signatures
-		severity string
+		have   *core.Signatures
+		want   *core.Signatures
+		filter *core.Filter
 	}{
 		"Filter nothing": {
-			have:     &core.Signatures{Plugins: []*core.Plugin{mock.FakePlugin}},
-			want:     &core.Signatures{Plugins: []*core.Plugin{mock.FakePlugin}},
-			severity: "Medium",
+			have:   &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
+			want:   &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
+			filter: &core.Filter{Names: []string{mock.FakeCheckStatusCode200.Name}},
+		},
+		"Filter one element": {
+			have:   &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
+			want:   &core.Signatures{},
+			filter: &core.Filter{Names: []string{"check's name that is not in the signatures"}},
+		},
+		"Keep exact id": {
+			have:   &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckHeaders, mock.FakeCheckHeaders2}}}},
+			want:   &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckHeaders}}}},
+			filter: &core.Filter{IDs: []string{mock.FakeCheckHeaders.ID}},
+		},
+		"Min severity and excluded tag": {
+			have:   &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckHeaders, mock.FakeCheckStatusCode200, mock.FakeCheckMatchOne}}}},
+			want:   &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckStatusCode200}}}},
+			filter: &core.Filter{MinSeverity: "Medium", ExcludeTags: []string{"noisy"}},
+		},
+		"Drop plugins left without checks": {
+			have: &core.Signatures{Plugins: []*core.Plugin{
+				{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckMatchOne}},
+				{Endpoint: "/fake", Checks: []*core.Check{mock.FakeCheckStatusCode500}},
+			}},
+			want:   &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/fake", Checks: []*core.Check{mock.FakeCheckStatusCode500}}}},
+			filter: &core.Filter{Severities: []string{"High"}},
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			tc.have.FilterBySeverity(tc.severity)
-			if !tc.want.Equals(tc.have) {
+			tc.have.Filter(tc.filter)
+			if !tc.have.Equals(tc.want) {
 				t.Errorf("expected: %v, got: %v", tc.want, tc.have)
 			}
 		})
 	}
 }
 
-func TestFilterByNames(t *testing.T) {
+func TestFiltered(t *testing.T) {
+	have := &core.Signatures{Plugins: []*core.Plugin{
+		{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckHeaders, mock.FakeCheckStatusCode200}},
+		{Endpoint: "/fake", Checks: []*core.Check{mock.FakeCheckStatusCode500}},
+	}}
+	want := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckStatusCode200}}}}
+
+	filtered := have.Filtered(&core.Filter{IDs: []string{mock.FakeCheckStatusCode200.ID}})
+	if !filtered.Equals(want) {
+		t.Errorf("expected: %v, got: %v", want, filtered)
+	}
+	if len(have.Plugins) != 2 || len(have.Plugins[0].Checks) != 2 || have.Plugins[0].Checks[0] != mock.FakeCheckHeaders {
+		t.Errorf("expected the filtered signatures to be left untouched, got: %v", have)
+	}
+}
+
+func TestGetEndpoints(t *testing.T) {
 	var tests = map[string]struct {
-		have  *core.Signatures
-		want  *core.Signatures
-		names []string
+		plugin *core.Plugin
+		want   []string
 	}{
-		"Filter nothing": {
-			have:  &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
-			want:  &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
-			names: []string{mock.FakeCheckStatusCode200.Name},
-		},
-		"Filter one element": {
-			have:  &core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}},
-			want:  &core.Signatures{},
-			names: []string{"check's name that is not in the signatures"},
-		},
+		"Endpoint":  {plugin: &core.Plugin{Endpoint: "/"}, want: []string{"/"}},
+		"Endpoints": {plugin: &core.Plugin{Endpoints: []string{"/a", "/b"}}, want: []string{"/a", "/b"}},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			tc.have.FilterByNames(tc.names)
-			if !tc.have.Equals(tc.want) {
-				t.Errorf("expected: %v, got: %v", tc.want, tc.have)
+			have := tc.plugin.GetEndpoints()
+			if !core.SliceStringEqual(have, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestConditions(t *testing.T) {
+	check := &core.Check{Matchers: core.Matchers{
+		StatusCode:   mock.FakeCheckStatusCode200.StatusCode,
+		MustMatchOne: []string{"MATCHONE", "MATCHTWO"},
+		NoHeaders:    []core.HeaderMatcher{{Name: "NoHeader", Value: "ok"}},
+	}}
+	want := []string{`status_code: 200`, `match: "MATCHONE", "MATCHTWO"`, `no_headers: "NoHeader:ok"`}
+	have := check.Conditions()
+	if !core.SliceStringEqual(have, want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+}
+
+func TestDefaultCheckID(t *testing.T) {
+	var tests = map[string]struct {
+		name string
+		want string
+	}{
+		"Simple name":        {name: "Git exposed", want: "git-exposed"},
+		"Special characters": {name: "Jenkins CVE-2018-1000861 (RCE)", want: "jenkins-cve-2018-1000861-rce"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.DefaultCheckID(tc.name)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
 			}
 		})
 	}
@@ -195,18 +257,38 @@ func TestCheckEquals(t *testing.T) {
 		want   bool
 	}{
 		"MustMatchOne not Equals": {
-			check1: &core.Check{MustMatchOne: []string{"MATCHONE", "MATCHTWO"}},
-			check2: &core.Check{MustMatchOne: []string{"MATCHFOUR", "MATCHTHREE"}},
+			check1: &core.Check{Matchers: core.Matchers{MustMatchOne: []string{"MATCHONE", "MATCHTWO"}}},
+			check2: &core.Check{Matchers: core.Matchers{MustMatchOne: []string{"MATCHFOUR", "MATCHTHREE"}}},
 			want:   false,
 		},
 		"MustMatchAll not Equals": {
-			check1: &core.Check{MustMatchAll: []string{"MATCHONE", "MATCHTWO"}},
-			check2: &core.Check{MustMatchAll: []string{"MATCHONE", "MATCHTHREE"}},
+			check1: &core.Check{Matchers: core.Matchers{MustMatchAll: []string{"MATCHONE", "MATCHTWO"}}},
+			check2: &core.Check{Matchers: core.Matchers{MustMatchAll: []string{"MATCHONE", "MATCHTHREE"}}},
 			want:   false,
 		},
 		"MustNotMatch Equals": {
-			check1: &core.Check{MustNotMatch: []string{"MATCHONE", "MATCHTWO"}},
-			check2: &core.Check{MustNotMatch: []string{"MATCHONE", "MATCHTHREE"}},
+			check1: &core.Check{Matchers: core.Matchers{MustNotMatch: []string{"MATCHONE", "MATCHTWO"}}},
+			check2: &core.Check{Matchers: core.Matchers{MustNotMatch: []string{"MATCHONE", "MATCHTHREE"}}},
+			want:   false,
+		},
+		"StatusCode not Equals": {
+			check1: &core.Check{Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}},
+			check2: &core.Check{Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200, 206)}},
+			want:   false,
+		},
+		"NotStatusCode not Equals": {
+			check1: &core.Check{Matchers: core.Matchers{NotStatusCode: core.NewStatusCodes(404)}},
+			check2: &core.Check{},
+			want:   false,
+		},
+		"ID not Equals": {
+			check1: &core.Check{ID: "id-1"},
+			check2: &core.Check{ID: "id-2"},
+			want:   false,
+		},
+		"Tags not Equals": {
+			check1: &core.Check{Tags: []string{"cms"}},
+			check2: &core.Check{Tags: []string{"iot"}},
 			want:   false,
 		},
 		"Name not Equals": {
@@ -230,13 +312,13 @@ func TestCheckEquals(t *testing.T) {
 			want:   false,
 		},
 		"Headers not Equals": {
-			check1: &core.Check{Headers: []string{"Header:OK"}},
-			check2: &core.Check{Headers: []string{"Header:notOK"}},
+			check1: &core.Check{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Name: "Header", Value: "OK"}}}},
+			check2: &core.Check{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Name: "Header", Value: "notOK"}}}},
 			want:   false,
 		},
 		"NoHeaders not Equals": {
-			check1: &core.Check{NoHeaders: []string{"NoHeader:OK"}},
-			check2: &core.Check{NoHeaders: []string{"NoHeader:notOK"}},
+			check1: &core.Check{Matchers: core.Matchers{NoHeaders: []core.HeaderMatcher{{Name: "NoHeader", Value: "OK"}}}},
+			check2: &core.Check{Matchers: core.Matchers{NoHeaders: []core.HeaderMatcher{{Name: "NoHeader", Value: "notOK"}}}},
 			want:   false,
 		},
 	}
@@ -283,3 +365,55 @@ func TestSliceStringEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestAddPlugin(t *testing.T) {
+	signatures := core.NewSignatures()
+	signatures.AddPlugin(&core.Plugin{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckStatusCode200}})
+	signatures.AddPlugin(&core.Plugin{Endpoint: "/", Checks: []*core.Check{mock.FakeCheckMatchOne}})
+	signatures.AddPlugin(&core.Plugin{Endpoint: "/", FollowRedirects: true, Checks: []*core.Check{mock.FakeCheckMatchAll}})
+
+	if len(signatures.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got: %v", len(signatures.Plugins))
+	}
+	if len(signatures.Plugins[0].Checks) != 2 {
+		t.Errorf("expected checks on the same endpoint to be merged, got: %v", signatures.Plugins[0].Checks)
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	var tests = map[string]struct {
+		data    string
+		wantID  string
+		wantErr bool
+	}{
+		"Default id": {
+			data:   "plugins:\n- endpoint: /\n  checks:\n  - name: Git Exposed\n    description: d\n    remediation: r\n    severity: High\n    status_code: 200\n",
+			wantID: "git-exposed",
+		},
+		"Missing endpoint": {
+			data:    "plugins:\n- checks:\n  - name: Git Exposed\n    description: d\n    remediation: r\n    severity: High\n",
+			wantErr: true,
+		},
+		"Invalid severity": {
+			data:    "plugins:\n- endpoint: /\n  checks:\n  - name: Git Exposed\n    description: d\n    remediation: r\n    severity: Huge\n",
+			wantErr: true,
+		},
+		"Duplicate id": {
+			data:    "plugins:\n- endpoint: /\n  checks:\n  - {name: A, id: a, description: d, remediation: r, severity: Low}\n  - {name: B, id: a, description: d, remediation: r, severity: Low}\n",
+			wantErr: true,
+		},
+		"Invalid YAML": {data: "plugins: [", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			signatures, err := core.ParseSignatures([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && signatures.Plugins[0].Checks[0].ID != tc.wantID {
+				t.Errorf("expected: %s, got: %s", tc.wantID, signatures.Plugins[0].Checks[0].ID)
+			}
+		})
+	}
+}
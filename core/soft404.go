@@ -0,0 +1,169 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gochopchop/internal"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Soft-404 modes, telling what happens to the findings whose response is the catch-all page of the target
+const (
+	Soft404Off       = "off"
+	Soft404Suppress  = "suppress"
+	Soft404Downgrade = "downgrade"
+)
+
+var soft404Modes = [3]string{Soft404Off, Soft404Suppress, Soft404Downgrade}
+
+func ValidSoft404Mode(mode string) bool {
+	for _, m := range soft404Modes {
+		if mode == m {
+			return true
+		}
+	}
+	return false
+}
+
+func Soft404ModesAsString() string {
+	return strings.Join(soft404Modes[:], ", ")
+}
+
+// Fingerprint identifies the response of a path, to tell if two paths got the same page
+type Fingerprint struct {
+	StatusCode int
+	Length     int
+	Hash       string
+}
+
+// NewFingerprint fingerprints the response of a path.
+// Catch-all pages often quote the requested path, so the echoes are removed from the body first.
+func NewFingerprint(resp *internal.HTTPResponse, echoes ...string) *Fingerprint {
+	body := removeEchoes(resp.Body, echoes)
+	sum := sha256.Sum256([]byte(body))
+	return &Fingerprint{
+		StatusCode: resp.StatusCode,
+		Length:     len(body),
+		Hash:       hex.EncodeToString(sum[:]),
+	}
+}
+
+// Match returns true if both fingerprints are the ones of the same page
+func (f *Fingerprint) Match(other *Fingerprint) bool {
+	return f.StatusCode == other.StatusCode && f.Length == other.Length && f.Hash == other.Hash
+}
+
+// mayMatch returns false once the response read so far can't be the page of the fingerprint anymore,
+// an echo cut at the end of the body being allowed for
+func (f *Fingerprint) mayMatch(resp *internal.HTTPResponse, echoes []string) bool {
+	if resp.StatusCode != f.StatusCode {
+		return false
+	}
+	slack := 0
+	for _, echo := range echoes {
+		if len(echo) > slack {
+			slack = len(echo)
+		}
+	}
+	return len(removeEchoes(resp.Body, echoes)) <= f.Length+slack
+}
+
+func removeEchoes(body string, echoes []string) string {
+	for _, echo := range echoes {
+		if echo != "" {
+			body = strings.ReplaceAll(body, echo, "")
+		}
+	}
+	return body
+}
+
+// endpointEchoes returns the strings a catch-all page may quote for the endpoint: itself and its path
+func endpointEchoes(endpoint string) []string {
+	echoes := []string{endpoint}
+	if i := strings.Index(endpoint, "?"); i >= 0 {
+		echoes = append(echoes, endpoint[:i])
+	}
+	return echoes
+}
+
+// randomPath returns a path that shouldn't exist on any target
+func randomPath() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return "/" + hex.EncodeToString(b)
+}
+
+// soft404Baselines holds the fingerprint of the catch-all page of each target, fetched once during a scan
+type soft404Baselines struct {
+	mux       sync.Mutex
+	baselines map[string]*soft404Baseline
+}
+
+type soft404Baseline struct {
+	once        sync.Once
+	fingerprint *Fingerprint
+}
+
+// get returns the baseline stored under key, calling fetch the first time, nil if the target has no catch-all page
+func (b *soft404Baselines) get(key string, fetch func() *Fingerprint) *Fingerprint {
+	b.mux.Lock()
+	baseline, ok := b.baselines[key]
+	if !ok {
+		baseline = &soft404Baseline{}
+		b.baselines[key] = baseline
+	}
+	b.mux.Unlock()
+	baseline.once.Do(func() {
+		baseline.fingerprint = fetch()
+	})
+	return baseline.fingerprint
+}
+
+// soft404Baseline returns the fingerprint of the response of a random path of the target, fetched with the fetcher
+// the plugin uses, nil when the target answers it with an error status.
+// The root endpoint is never compared, the catch-all page of a target often being its home page.
+func (s Scanner) soft404Baseline(baselines *soft404Baselines, job workerJob) *Fingerprint {
+	if s.Soft404 == "" || s.Soft404 == Soft404Off || job.endpoint == "/" {
+		return nil
+	}
+	key := fmt.Sprintf("%s %t", job.target, job.plugin.FollowRedirects)
+	return baselines.get(key, func() *Fingerprint {
+		path := randomPath()
+		fetcher := s.fetcher(job.plugin)
+		var resp *internal.HTTPResponse
+		var err error
+		if streamFetcher, ok := fetcher.(IStreamFetcher); ok {
+			resp, err = streamFetcher.FetchUntil(job.ctx, job.target+path, nil)
+		} else {
+			resp, err = fetcher.Fetch(job.target + path)
+		}
+		if err != nil {
+			log.Debugf("Could not fetch the soft 404 baseline of %s : %v", job.target, err)
+			return nil
+		}
+		if resp.StatusCode >= 400 {
+			// a real error page, checks looking for error pages have to match it
+			return nil
+		}
+		return NewFingerprint(resp, path)
+	})
+}
+
+// soft404Output applies the soft-404 mode to a finding whose response is the catch-all page of the target,
+// returning false if it is suppressed
+func (s Scanner) soft404Output(output *Output) bool {
+	if s.Soft404 == Soft404Downgrade {
+		output.Severity = "Informational"
+		output.Tags = append(append([]string{}, output.Tags...), "soft-404")
+		return true
+	}
+	log.Debugf("Soft 404 ignored : %s on %s", output.ID, output.URL)
+	return false
+}
@@ -0,0 +1,110 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/mock"
+	"strings"
+	"testing"
+)
+
+func TestScanSoft404(t *testing.T) {
+	signatures := &core.Signatures{Plugins: []*core.Plugin{
+		{Endpoint: "/", Checks: []*core.Check{{ID: "root", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}}},
+		{Endpoint: "/admin", Checks: []*core.Check{{ID: "admin", Severity: "High", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}}},
+		{Endpoint: "/.git/config", Checks: []*core.Check{{ID: "git", Severity: "High", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}}},
+		{Endpoint: "/error", Checks: []*core.Check{{ID: "error", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(404)}}}},
+	}}
+	pages := mock.FakeFetcherWithoutNetclient{
+		"http://site/.git/config": {StatusCode: 200, Body: "[core]"},
+	}
+	var tests = map[string]struct {
+		mode     string
+		catchAll bool
+		want     map[string]string
+	}{
+		"Suppress":      {mode: core.Soft404Suppress, catchAll: true, want: map[string]string{"root": "", "git": "High"}},
+		"Downgrade":     {mode: core.Soft404Downgrade, catchAll: true, want: map[string]string{"root": "", "admin": "Informational", "git": "High"}},
+		"Off":           {mode: core.Soft404Off, catchAll: true, want: map[string]string{"root": "", "admin": "High", "git": "High"}},
+		"Real 404 page": {mode: core.Soft404Suppress, want: map[string]string{"git": "High", "error": ""}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the other paths get a 404 or a catch-all page quoting the path
+			fetcher := mock.NewFetcher(pages, mock.WithFallback(func(url string) (*internal.HTTPResponse, error) {
+				if !tc.catchAll {
+					return &internal.HTTPResponse{StatusCode: 404, Body: "Not found"}, nil
+				}
+				path := strings.TrimPrefix(url, "http://site")
+				return &internal.HTTPResponse{StatusCode: 200, Body: fmt.Sprintf("<h1>Welcome</h1><p>You asked for %s</p>", path)}, nil
+			}))
+			scanner := core.NewScanner(fetcher, fetcher, signatures, 2)
+			scanner.Soft404 = tc.mode
+			output, err := scanner.Scan(context.Background(), []string{"http://site"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			have := make(map[string]string)
+			for _, o := range output {
+				have[o.ID] = o.Severity
+			}
+			if len(have) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, have)
+			}
+			for id, severity := range tc.want {
+				if s, ok := have[id]; !ok || s != severity {
+					t.Errorf("expected: %v, got: %v", tc.want, have)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintMatch(t *testing.T) {
+	page := func(status int, body string) *internal.HTTPResponse {
+		return &internal.HTTPResponse{StatusCode: status, Body: body}
+	}
+	baseline := core.NewFingerprint(page(200, "Page /random not found"), "/random")
+	var tests = map[string]struct {
+		fingerprint *core.Fingerprint
+		match       bool
+	}{
+		"Same page":        {fingerprint: core.NewFingerprint(page(200, "Page /random not found"), "/random"), match: true},
+		"Path quoted":      {fingerprint: core.NewFingerprint(page(200, "Page /admin not found"), "/admin"), match: true},
+		"Other status":     {fingerprint: core.NewFingerprint(page(500, "Page /admin not found"), "/admin"), match: false},
+		"Other body":       {fingerprint: core.NewFingerprint(page(200, "Admin panel"), "/admin"), match: false},
+		"Path not removed": {fingerprint: core.NewFingerprint(page(200, "Page /admin not found")), match: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := baseline.Match(tc.fingerprint); have != tc.match {
+				t.Errorf("expected: %v, got: %v", tc.match, have)
+			}
+		})
+	}
+}
+
+func TestScanSoft404ReadsBaselinePage(t *testing.T) {
+	body := strings.Repeat("catch-all ", 100)
+	fetcher := mock.NewFetcher(nil, mock.WithChunks(10), mock.WithFallback(func(url string) (*internal.HTTPResponse, error) {
+		return &internal.HTTPResponse{StatusCode: 200, Body: body}, nil
+	}))
+	check := &core.Check{ID: "admin", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/admin", Checks: []*core.Check{check}}}}
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 1)
+	scanner.Soft404 = core.Soft404Suppress
+	output, err := scanner.Scan(context.Background(), []string{"http://site"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output) != 0 {
+		t.Errorf("expected the finding to be suppressed, got: %v", output)
+	}
+	if read := fetcher.Read("http://site/admin"); read != len(body) {
+		t.Errorf("expected whole body read, got %d bytes", read)
+	}
+}
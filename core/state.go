@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ScanState is the progress of a scan: the jobs completed and their findings, saved to resume an interrupted scan.
+// A job is the run of the checks of a plugin on one URL.
+type ScanState struct {
+	mux       sync.Mutex
+	urls      []string
+	completed map[string]bool
+	outputs   []Output
+}
+
+// scanStateFile is the JSON document a scan state is saved as
+type scanStateFile struct {
+	Urls      []string `json:"urls"`
+	Completed []string `json:"completed"`
+	Outputs   []Output `json:"outputs"`
+}
+
+// NewScanState returns the empty state of a scan of the urls
+func NewScanState(urls []string) *ScanState {
+	return &ScanState{urls: urls, completed: make(map[string]bool), outputs: make([]Output, 0)}
+}
+
+// LoadScanState reads a state saved by Marshal
+func LoadScanState(data []byte) (*ScanState, error) {
+	var file scanStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("Invalid scan state : %v", err)
+	}
+	state := NewScanState(file.Urls)
+	for _, key := range file.Completed {
+		state.completed[key] = true
+	}
+	if file.Outputs != nil {
+		state.outputs = file.Outputs
+	}
+	return state, nil
+}
+
+// Marshal returns the state as JSON
+func (s *ScanState) Marshal() ([]byte, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	file := scanStateFile{Urls: s.urls, Completed: make([]string, 0, len(s.completed)), Outputs: s.outputs}
+	for key := range s.completed {
+		file.Completed = append(file.Completed, key)
+	}
+	return json.Marshal(file)
+}
+
+// Urls returns the urls of the scan
+func (s *ScanState) Urls() []string {
+	return s.urls
+}
+
+// Outputs returns the findings of the completed jobs
+func (s *ScanState) Outputs() []Output {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]Output{}, s.outputs...)
+}
+
+// Completed returns the number of jobs completed
+func (s *ScanState) Completed() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.completed)
+}
+
+func (s *ScanState) done(key string) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.completed[key]
+}
+
+// complete records a job as completed along with its findings, both at once so that an interrupted job is run again
+func (s *ScanState) complete(key string, outputs []Output) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.completed[key] = true
+	s.outputs = append(s.outputs, outputs...)
+}
+
+// jobKey identifies a job across runs, by URL and checks of the plugin
+func jobKey(url string, plugin *Plugin) string {
+	ids := make([]string, len(plugin.Checks))
+	for i, check := range plugin.Checks {
+		ids[i] = check.ID
+	}
+	return fmt.Sprintf("%s %t %s", url, plugin.FollowRedirects, strings.Join(ids, ","))
+}
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/mock"
+	"testing"
+)
+
+func TestScanResume(t *testing.T) {
+	check := func(id string) *core.Check {
+		return &core.Check{ID: id, Name: id, Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}}
+	}
+	signatures := &core.Signatures{Plugins: []*core.Plugin{
+		{Endpoint: "/a", Checks: []*core.Check{check("a")}},
+		{Endpoint: "/b", Checks: []*core.Check{check("b")}},
+	}}
+	urls := []string{"http://site"}
+
+	first := mock.NewFetcher(nil, mock.WithStatusCode(200))
+	scanner := core.NewScanner(first, first, &core.Signatures{Plugins: signatures.Plugins[:1]}, 1)
+	scanner.State = core.NewScanState(urls)
+	if _, err := scanner.Scan(context.Background(), urls); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := scanner.State.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := core.LoadScanState(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(state.Urls()) != 1 || state.Completed() != 1 || len(state.Outputs()) != 1 {
+		t.Fatalf("expected the state of the first job, got: %s", data)
+	}
+
+	second := mock.NewFetcher(nil, mock.WithStatusCode(200))
+	scanner = core.NewScanner(second, second, signatures, 1)
+	scanner.State = state
+	output, err := scanner.Scan(context.Background(), state.Urls())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Fetched("http://site/a") != 0 || second.Fetched("http://site/b") != 1 {
+		t.Errorf("expected only the remaining job to run, got: %d and %d requests", second.Fetched("http://site/a"), second.Fetched("http://site/b"))
+	}
+	if len(output) != 1 || output[0].ID != "b" {
+		t.Errorf("expected the finding of the remaining job, got: %v", output)
+	}
+	if state.Completed() != 2 || len(state.Outputs()) != 2 {
+		t.Errorf("expected both jobs and findings in the state, got: %d jobs, %v", state.Completed(), state.Outputs())
+	}
+}
+
+func TestScanCancelledDoesntCompleteJobs(t *testing.T) {
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/a", Checks: []*core.Check{{ID: "a"}}}}}
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 1)
+	scanner.State = core.NewScanState([]string{"http://site"})
+	if _, err := scanner.Scan(ctx, []string{"http://site"}); err != context.Canceled {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+	if scanner.State.Completed() != 0 {
+		t.Errorf("expected no completed job, got: %d", scanner.State.Completed())
+	}
+}
+
+func TestLoadScanStateInvalid(t *testing.T) {
+	if _, err := core.LoadScanState([]byte(`{"urls": "http://site"}`)); err == nil {
+		t.Errorf("expected an error")
+	}
+}
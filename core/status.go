@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusCodeRange is an inclusive range of HTTP status codes, a single code having Min equal to Max
+type StatusCodeRange struct {
+	Min int
+	Max int
+}
+
+// StatusCodes is a set of HTTP status codes.
+// In signatures it is written as a code (200), a class ("2xx"), a range ("200-299") or a list of these.
+type StatusCodes []StatusCodeRange
+
+// NewStatusCodes returns the set holding exactly these codes
+func NewStatusCodes(codes ...int) StatusCodes {
+	s := make(StatusCodes, len(codes))
+	for i, code := range codes {
+		s[i] = StatusCodeRange{Min: code, Max: code}
+	}
+	return s
+}
+
+// ParseStatusCodeRange parses a code, a class like "2xx" or a range like "200-299"
+func ParseStatusCodeRange(s string) (StatusCodeRange, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") {
+		class, err := strconv.Atoi(s[:1])
+		if err != nil {
+			return StatusCodeRange{}, fmt.Errorf("Invalid status code class : %s", s)
+		}
+		return StatusCodeRange{Min: class * 100, Max: class*100 + 99}, nil
+	}
+	if bounds := strings.SplitN(s, "-", 2); len(bounds) == 2 {
+		min, errMin := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		max, errMax := strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if errMin != nil || errMax != nil {
+			return StatusCodeRange{}, fmt.Errorf("Invalid status code range : %s", s)
+		}
+		return StatusCodeRange{Min: min, Max: max}, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return StatusCodeRange{}, fmt.Errorf("Invalid status code : %s", s)
+	}
+	return StatusCodeRange{Min: code, Max: code}, nil
+}
+
+func (r StatusCodeRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	if r.Min%100 == 0 && r.Max == r.Min+99 {
+		return fmt.Sprintf("%dxx", r.Min/100)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+func (s *StatusCodes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var values []string
+	if err := unmarshal(&values); err != nil {
+		var value string
+		if err := unmarshal(&value); err != nil {
+			return err
+		}
+		values = []string{value}
+	}
+	codes := make(StatusCodes, 0, len(values))
+	for _, value := range values {
+		r, err := ParseStatusCodeRange(value)
+		if err != nil {
+			return err
+		}
+		codes = append(codes, r)
+	}
+	*s = codes
+	return nil
+}
+
+func (s StatusCodes) MarshalYAML() (interface{}, error) {
+	values := make([]interface{}, len(s))
+	for i, r := range s {
+		if r.Min == r.Max {
+			values[i] = r.Min
+		} else {
+			values[i] = r.String()
+		}
+	}
+	if len(values) == 1 {
+		return values[0], nil
+	}
+	return values, nil
+}
+
+// Contains returns true if the code is in one of the ranges
+func (s StatusCodes) Contains(code int) bool {
+	for _, r := range s {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if a range is empty or outside of the HTTP status codes
+func (s StatusCodes) Validate() error {
+	for _, r := range s {
+		if r.Min < 100 || r.Max > 599 || r.Min > r.Max {
+			return fmt.Errorf("Invalid status code : %s. Status codes should be between 100 and 599", r)
+		}
+	}
+	return nil
+}
+
+func (s StatusCodes) String() string {
+	values := make([]string, len(s))
+	for i, r := range s {
+		values[i] = r.String()
+	}
+	return strings.Join(values, ", ")
+}
+
+func (self StatusCodes) Equals(codes StatusCodes) bool {
+	if len(self) != len(codes) {
+		return false
+	}
+	for i, r := range self {
+		if r != codes[i] {
+			return false
+		}
+	}
+	return true
+}
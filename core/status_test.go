@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestStatusCodesUnmarshal(t *testing.T) {
+	var tests = map[string]struct {
+		yaml string
+		want core.StatusCodes
+		err  bool
+	}{
+		"Single code": {yaml: "200", want: core.NewStatusCodes(200)},
+		"List":        {yaml: "[200, 206]", want: core.NewStatusCodes(200, 206)},
+		"Class":       {yaml: `"2xx"`, want: core.StatusCodes{{Min: 200, Max: 299}}},
+		"Range":       {yaml: `"401-403"`, want: core.StatusCodes{{Min: 401, Max: 403}}},
+		"Mixed list":  {yaml: `[301, "4xx"]`, want: core.StatusCodes{{Min: 301, Max: 301}, {Min: 400, Max: 499}}},
+		"Invalid":     {yaml: `"ok"`, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var have core.StatusCodes
+			err := yaml.Unmarshal([]byte(tc.yaml), &have)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected an error, got: %v", have)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !have.Equals(tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestStatusCodesMarshal(t *testing.T) {
+	codes := core.StatusCodes{{Min: 200, Max: 200}, {Min: 300, Max: 399}}
+	data, err := yaml.Marshal(codes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "- 200\n- 3xx\n"
+	if string(data) != want {
+		t.Errorf("expected: %q, got: %q", want, string(data))
+	}
+}
+
+func TestStatusCodesContains(t *testing.T) {
+	codes := core.StatusCodes{{Min: 200, Max: 200}, {Min: 400, Max: 499}}
+	var tests = map[string]struct {
+		code int
+		want bool
+	}{
+		"Code":          {code: 200, want: true},
+		"In range":      {code: 404, want: true},
+		"Out of ranges": {code: 206, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := codes.Contains(tc.code)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestStatusCodesValidate(t *testing.T) {
+	var tests = map[string]struct {
+		codes core.StatusCodes
+		valid bool
+	}{
+		"Valid":          {codes: core.StatusCodes{{Min: 200, Max: 299}}, valid: true},
+		"Out of bounds":  {codes: core.NewStatusCodes(700), valid: false},
+		"Reversed range": {codes: core.StatusCodes{{Min: 299, Max: 200}}, valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.codes.Validate()
+			if (err == nil) != tc.valid {
+				t.Errorf("expected valid: %v, got: %v", tc.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Values of Output.Suppression
+const (
+	SuppressionActive  = "suppressed"
+	SuppressionExpired = "expired"
+)
+
+// suppressionDateFormat is the format of the expiry dates of suppressions
+const suppressionDateFormat = "2006-01-02"
+
+// Suppressions lists the findings accepted as risks, loaded from a suppressions file
+type Suppressions struct {
+	Suppressions []*Suppression `yaml:"suppressions"`
+}
+
+// Suppression accepts the findings matching every field set among ID, Name and URL
+type Suppression struct {
+	ID   string `yaml:"id,omitempty"`
+	Name string `yaml:"name,omitempty"`
+	// URL is a pattern of the URL of the finding, * matching any characters
+	URL string `yaml:"url,omitempty"`
+	// Expires is the last day the suppression applies, YYYY-MM-DD, empty for no expiry
+	Expires       string `yaml:"expires,omitempty"`
+	Justification string `yaml:"justification"`
+	url           *regexp.Regexp
+	expires       time.Time
+}
+
+// Validate returns an error if a suppression matches every finding, has no justification,
+// an invalid URL pattern or expiry date. It has to be called before applying the suppressions.
+func (s *Suppressions) Validate() error {
+	for i, suppression := range s.Suppressions {
+		if suppression.ID == "" && suppression.Name == "" && suppression.URL == "" {
+			return fmt.Errorf("Suppression %d matches every finding, it should set an id, a name or an url", i+1)
+		}
+		if strings.TrimSpace(suppression.Justification) == "" {
+			return fmt.Errorf("Suppression %d has no justification", i+1)
+		}
+		if suppression.URL != "" {
+			pattern, err := urlPattern(suppression.URL)
+			if err != nil {
+				return fmt.Errorf("Invalid url of suppression %d : %s", i+1, suppression.URL)
+			}
+			suppression.url = pattern
+		}
+		if suppression.Expires != "" {
+			expires, err := time.Parse(suppressionDateFormat, suppression.Expires)
+			if err != nil {
+				return fmt.Errorf("Invalid expiry date of suppression %d : %s. Please use : YYYY-MM-DD", i+1, suppression.Expires)
+			}
+			suppression.expires = expires
+		}
+	}
+	return nil
+}
+
+// urlPattern compiles a URL pattern, * matching any characters
+func urlPattern(url string) (*regexp.Regexp, error) {
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(url), `\*`, ".*")
+	return regexp.Compile("^" + pattern + "$")
+}
+
+// Match returns true if the suppression applies to the finding, whether it has expired or not.
+// An url pattern is only matched once compiled by Validate.
+func (s *Suppression) Match(output Output) bool {
+	if s.ID != "" && s.ID != output.ID {
+		return false
+	}
+	if s.Name != "" && !strings.EqualFold(s.Name, output.Name) {
+		return false
+	}
+	if s.URL != "" && (s.url == nil || !s.url.MatchString(output.URL)) {
+		return false
+	}
+	return true
+}
+
+// Expired returns true once the expiry day of the suppression is over
+func (s *Suppression) Expired(now time.Time) bool {
+	expires := s.expires
+	if expires.IsZero() && s.Expires != "" {
+		parsed, err := time.Parse(suppressionDateFormat, s.Expires)
+		if err != nil {
+			// an invalid expiry date never makes a suppression permanent
+			return true
+		}
+		expires = parsed
+	}
+	return !expires.IsZero() && !now.Before(expires.AddDate(0, 0, 1))
+}
+
+// Apply marks the findings matched by a suppression as suppressed with its justification,
+// or flags them when every matching suppression has expired
+func (s *Suppressions) Apply(outputs []Output, now time.Time) []Output {
+	applied := make([]Output, len(outputs))
+	for i, output := range outputs {
+		for _, suppression := range s.Suppressions {
+			if !suppression.Match(output) {
+				continue
+			}
+			if suppression.Expired(now) {
+				output.Suppression = SuppressionExpired
+				output.Justification = suppression.Justification
+				continue
+			}
+			output.Suppression = SuppressionActive
+			output.Justification = suppression.Justification
+			break
+		}
+		applied[i] = output
+	}
+	return applied
+}
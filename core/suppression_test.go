@@ -0,0 +1,98 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+	"time"
+)
+
+func TestSuppressionsValidate(t *testing.T) {
+	var tests = map[string]struct {
+		suppression *core.Suppression
+		wantErr     bool
+	}{
+		"Valid":                {suppression: &core.Suppression{ID: "git", URL: "https://*.example.com/*", Expires: "2030-01-31", Justification: "accepted"}, wantErr: false},
+		"No criterion":         {suppression: &core.Suppression{Justification: "accepted"}, wantErr: true},
+		"No justification":     {suppression: &core.Suppression{ID: "git", Justification: " "}, wantErr: true},
+		"Invalid expiry":       {suppression: &core.Suppression{ID: "git", Expires: "31/01/2030", Justification: "accepted"}, wantErr: true},
+		"Regexp chars escaped": {suppression: &core.Suppression{URL: "https://example.com/(", Justification: "accepted"}, wantErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suppressions := &core.Suppressions{Suppressions: []*core.Suppression{tc.suppression}}
+			if err := suppressions.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSuppressionsApply(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	var tests = map[string]struct {
+		suppression   core.Suppression
+		output        core.Output
+		want          string
+		justification string
+	}{
+		"By id":               {suppression: core.Suppression{ID: "git"}, output: core.Output{ID: "git", URL: "http://a/.git/config"}, want: core.SuppressionActive},
+		"By name":             {suppression: core.Suppression{Name: "Git exposed"}, output: core.Output{Name: "git EXPOSED"}, want: core.SuppressionActive},
+		"Other id":            {suppression: core.Suppression{ID: "git"}, output: core.Output{ID: "svn"}, want: ""},
+		"URL pattern":         {suppression: core.Suppression{URL: "https://*.example.com/status*"}, output: core.Output{URL: "https://www.example.com/status.shtml"}, want: core.SuppressionActive},
+		"URL not matched":     {suppression: core.Suppression{URL: "https://*.example.com/status*"}, output: core.Output{URL: "https://example.org/status.shtml"}, want: ""},
+		"Id and URL":          {suppression: core.Suppression{ID: "git", URL: "http://a/*"}, output: core.Output{ID: "git", URL: "http://b/.git/config"}, want: ""},
+		"Expires today":       {suppression: core.Suppression{ID: "git", Expires: "2024-06-15"}, output: core.Output{ID: "git"}, want: core.SuppressionActive},
+		"Expired":             {suppression: core.Suppression{ID: "git", Expires: "2024-06-14"}, output: core.Output{ID: "git"}, want: core.SuppressionExpired},
+		"Justification added": {suppression: core.Suppression{ID: "git"}, output: core.Output{ID: "git"}, want: core.SuppressionActive, justification: "accepted"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc.suppression.Justification = "accepted"
+			suppressions := &core.Suppressions{Suppressions: []*core.Suppression{&tc.suppression}}
+			if err := suppressions.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			have := suppressions.Apply([]core.Output{tc.output}, now)[0]
+			if have.Suppression != tc.want {
+				t.Errorf("expected: %q, got: %q", tc.want, have.Suppression)
+			}
+			if tc.justification != "" && have.Justification != tc.justification {
+				t.Errorf("expected justification: %q, got: %q", tc.justification, have.Justification)
+			}
+		})
+	}
+}
+
+func TestSuppressionsApplyPrefersActive(t *testing.T) {
+	suppressions := &core.Suppressions{Suppressions: []*core.Suppression{
+		{ID: "git", Expires: "2020-01-01", Justification: "old"},
+		{ID: "git", Justification: "renewed"},
+	}}
+	if err := suppressions.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	have := suppressions.Apply([]core.Output{{ID: "git"}}, time.Now())[0]
+	if have.Suppression != core.SuppressionActive || have.Justification != "renewed" {
+		t.Errorf("expected the active suppression to apply, got: %v", have)
+	}
+}
+
+func TestSuppressionsApplyWithoutValidate(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	suppressions := &core.Suppressions{Suppressions: []*core.Suppression{
+		{URL: "http://a/*", Justification: "accepted"},
+		{ID: "svn", Expires: "2024-06-14", Justification: "old"},
+		{ID: "env", Expires: "someday", Justification: "invalid"},
+	}}
+	outputs := []core.Output{{ID: "git", URL: "http://a/.git/config"}, {ID: "git", URL: "http://b/.git/config"}, {ID: "svn"}, {ID: "env"}}
+	// the url pattern isn't compiled, the suppression suppresses nothing rather than failing
+	want := []string{"", "", core.SuppressionExpired, core.SuppressionExpired}
+
+	for i, have := range suppressions.Apply(outputs, now) {
+		if have.Suppression != want[i] {
+			t.Errorf("%v: expected: %q, got: %q", outputs[i], want[i], have.Suppression)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"gochopchop/internal"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var tlsVersions = map[string]uint16{
+	"TLS1.0": tls.VersionTLS10,
+	"TLS1.1": tls.VersionTLS11,
+	"TLS1.2": tls.VersionTLS12,
+	"TLS1.3": tls.VersionTLS13,
+}
+
+// TLSMatcher is a condition on the TLS configuration of the server of the response and its certificate.
+// Every field set must be met, a response received without TLS never matching.
+type TLSMatcher struct {
+	// ExpiresWithin matches certificates expiring in less than this number of days, 0 meaning already expired
+	ExpiresWithin *int `yaml:"expires_within,omitempty"`
+	// Issuer is a substring of the certificate issuer
+	Issuer           string `yaml:"issuer,omitempty"`
+	SelfSigned       bool   `yaml:"self_signed,omitempty"`
+	HostnameMismatch bool   `yaml:"hostname_mismatch,omitempty"`
+	// Versions are the protocol versions to match, among TLS1.0, TLS1.1, TLS1.2 and TLS1.3, one of them having to be accepted by the server
+	Versions []string `yaml:"versions,omitempty"`
+	// Ciphers are substrings of the name of a cipher suite accepted by the server, like CBC or 3DES
+	Ciphers []string `yaml:"ciphers,omitempty"`
+	// KeySizeBelow matches certificates whose RSA public key has less bits, other keys never matching
+	KeySizeBelow int `yaml:"key_size_below,omitempty"`
+}
+
+// Match returns true if the TLS state of the server satisfies every field of the matcher,
+// rawURL being the URL the response was received from
+func (m *TLSMatcher) Match(state *internal.TLSState, rawURL string) bool {
+	if state == nil || len(state.Certificates) == 0 {
+		return false
+	}
+	cert := state.Certificates[0]
+	if m.ExpiresWithin != nil && !time.Now().AddDate(0, 0, *m.ExpiresWithin).After(cert.NotAfter) {
+		return false
+	}
+	if m.Issuer != "" && !strings.Contains(cert.Issuer.String(), m.Issuer) {
+		return false
+	}
+	if m.SelfSigned && !isSelfSigned(cert) {
+		return false
+	}
+	if m.HostnameMismatch {
+		hostname := state.ServerName
+		// no server name is sent to IP targets, the certificate is checked against the host of the URL
+		if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+			hostname = u.Hostname()
+		}
+		if hostname == "" || cert.VerifyHostname(hostname) == nil {
+			return false
+		}
+	}
+	if len(m.Versions) > 0 && !m.matchVersion(state.Versions) {
+		return false
+	}
+	if len(m.Ciphers) > 0 && !m.matchCipher(state.CipherSuites) {
+		return false
+	}
+	if m.KeySizeBelow > 0 && !rsaKeyBelow(cert, m.KeySizeBelow) {
+		return false
+	}
+	return true
+}
+
+func (m *TLSMatcher) matchVersion(versions []uint16) bool {
+	for _, version := range versions {
+		for _, name := range m.Versions {
+			if tlsVersions[strings.ToUpper(name)] == version {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *TLSMatcher) matchCipher(ids []uint16) bool {
+	for _, id := range ids {
+		name := tls.CipherSuiteName(id)
+		for _, cipher := range m.Ciphers {
+			if strings.Contains(name, strings.ToUpper(cipher)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSelfSigned returns true if the certificate is signed by its own key, whether it is a CA or not
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func rsaKeyBelow(cert *x509.Certificate, size int) bool {
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	return ok && key.N.BitLen() < size
+}
+
+// Validate returns an error if the matcher is empty or uses an unknown protocol version
+func (m *TLSMatcher) Validate() error {
+	if len(m.Conditions()) == 0 {
+		return fmt.Errorf("Empty tls condition, it should set at least one field")
+	}
+	if m.ExpiresWithin != nil && *m.ExpiresWithin < 0 {
+		return fmt.Errorf("Invalid tls expires_within : %d. It should be a number of days", *m.ExpiresWithin)
+	}
+	for _, version := range m.Versions {
+		if _, found := tlsVersions[strings.ToUpper(version)]; !found {
+			return fmt.Errorf("Invalid TLS version : %s. Please use : TLS1.0, TLS1.1, TLS1.2, TLS1.3", version)
+		}
+	}
+	return nil
+}
+
+// Conditions describes the fields of the matcher, one field per element
+func (m *TLSMatcher) Conditions() []string {
+	var conditions []string
+	if m.ExpiresWithin != nil {
+		conditions = append(conditions, fmt.Sprintf("expires_within: %d days", *m.ExpiresWithin))
+	}
+	if m.Issuer != "" {
+		conditions = append(conditions, fmt.Sprintf("issuer: %q", m.Issuer))
+	}
+	if m.SelfSigned {
+		conditions = append(conditions, "self_signed")
+	}
+	if m.HostnameMismatch {
+		conditions = append(conditions, "hostname_mismatch")
+	}
+	if len(m.Versions) > 0 {
+		conditions = append(conditions, fmt.Sprintf("versions: %s", strings.Join(m.Versions, ", ")))
+	}
+	if len(m.Ciphers) > 0 {
+		conditions = append(conditions, fmt.Sprintf("ciphers: %s", quoteAll(m.Ciphers)))
+	}
+	if m.KeySizeBelow > 0 {
+		conditions = append(conditions, fmt.Sprintf("key_size_below: %d", m.KeySizeBelow))
+	}
+	return conditions
+}
+
+func (m *TLSMatcher) String() string {
+	return strings.Join(m.Conditions(), ", ")
+}
+
+func (self *TLSMatcher) Equals(m *TLSMatcher) bool {
+	if self == nil || m == nil {
+		return self == m
+	}
+	if (self.ExpiresWithin == nil) != (m.ExpiresWithin == nil) {
+		return false
+	}
+	if self.ExpiresWithin != nil && *self.ExpiresWithin != *m.ExpiresWithin {
+		return false
+	}
+	return self.Issuer == m.Issuer && self.SelfSigned == m.SelfSigned && self.HostnameMismatch == m.HostnameMismatch &&
+		SliceStringEqual(self.Versions, m.Versions) && SliceStringEqual(self.Ciphers, m.Ciphers) &&
+		self.KeySizeBelow == m.KeySizeBelow
+}
+
+// tlsOnly returns true if the check only has tls conditions, so that it can be evaluated on the TLS configuration
+// of a server whose certificate made the request fail
+func (check *Check) tlsOnly() bool {
+	if check.Matchers.TLS == nil && check.Condition == nil {
+		return false
+	}
+	return tlsOnlyMatchers(&check.Matchers) && (check.Condition == nil || check.Condition.tlsOnly())
+}
+
+func (c *Condition) tlsOnly() bool {
+	if !tlsOnlyMatchers(&c.Matchers) {
+		return false
+	}
+	for _, condition := range append(append([]*Condition{}, c.All...), c.Any...) {
+		if !condition.tlsOnly() {
+			return false
+		}
+	}
+	return c.Not == nil || c.Not.tlsOnly()
+}
+
+func tlsOnlyMatchers(m *Matchers) bool {
+	conditions := m.Conditions()
+	return len(conditions) == 0 || (len(conditions) == 1 && m.TLS != nil)
+}
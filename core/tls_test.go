@@ -0,0 +1,169 @@
+package core_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/mock"
+	"math/big"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// selfSignedCert creates a certificate for example.com expiring in the given number of days
+func selfSignedCert(t *testing.T, days int, rsaKey bool) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com", Organization: []string{"Chopchop test"}},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().AddDate(-1, 0, 0),
+		NotAfter:     time.Now().AddDate(0, 0, days),
+	}
+	var pub, priv interface{}
+	if rsaKey {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, priv = &key.PublicKey, key
+	} else {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, priv = &key.PublicKey, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestTLSMatcherMatch(t *testing.T) {
+	days := func(n int) *int { return &n }
+	rsaCert := selfSignedCert(t, 10, true)
+	ecdsaCert := selfSignedCert(t, -1, false)
+	state := &internal.TLSState{
+		Versions:     []uint16{tls.VersionTLS10},
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+		ServerName:   "example.com",
+		Certificates: []*x509.Certificate{rsaCert},
+	}
+	expired := &internal.TLSState{
+		Versions:     []uint16{tls.VersionTLS13},
+		CipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256},
+		ServerName:   "www.example.org",
+		Certificates: []*x509.Certificate{ecdsaCert},
+	}
+	noServerName := &internal.TLSState{
+		Versions:     []uint16{tls.VersionTLS12},
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		Certificates: []*x509.Certificate{rsaCert},
+	}
+	downgradable := &internal.TLSState{
+		Versions:     []uint16{tls.VersionTLS13, tls.VersionTLS10},
+		CipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_RC4_128_SHA},
+		Certificates: []*x509.Certificate{ecdsaCert},
+	}
+	var tests = map[string]struct {
+		matcher core.TLSMatcher
+		state   *internal.TLSState
+		url     string
+		want    bool
+	}{
+		"No TLS":                      {matcher: core.TLSMatcher{SelfSigned: true}, state: nil, want: false},
+		"Expiring soon":               {matcher: core.TLSMatcher{ExpiresWithin: days(30)}, state: state, want: true},
+		"Not expiring soon":           {matcher: core.TLSMatcher{ExpiresWithin: days(5)}, state: state, want: false},
+		"Not expired":                 {matcher: core.TLSMatcher{ExpiresWithin: days(0)}, state: state, want: false},
+		"Expired":                     {matcher: core.TLSMatcher{ExpiresWithin: days(0)}, state: expired, want: true},
+		"Issuer":                      {matcher: core.TLSMatcher{Issuer: "Chopchop test"}, state: state, want: true},
+		"Other issuer":                {matcher: core.TLSMatcher{Issuer: "Let's Encrypt"}, state: state, want: false},
+		"Self-signed":                 {matcher: core.TLSMatcher{SelfSigned: true}, state: state, want: true},
+		"Hostname match":              {matcher: core.TLSMatcher{HostnameMismatch: true}, state: state, want: false},
+		"Hostname mismatch":           {matcher: core.TLSMatcher{HostnameMismatch: true}, state: expired, want: true},
+		"Hostname of the URL":         {matcher: core.TLSMatcher{HostnameMismatch: true}, state: noServerName, url: "https://example.com:8443/admin", want: false},
+		"IP target mismatch":          {matcher: core.TLSMatcher{HostnameMismatch: true}, state: noServerName, url: "https://10.0.0.1/", want: true},
+		"Deprecated version":          {matcher: core.TLSMatcher{Versions: []string{"TLS1.0", "TLS1.1"}}, state: state, want: true},
+		"Recent version":              {matcher: core.TLSMatcher{Versions: []string{"TLS1.0", "TLS1.1"}}, state: expired, want: false},
+		"Deprecated version accepted": {matcher: core.TLSMatcher{Versions: []string{"TLS1.0", "TLS1.1"}}, state: downgradable, want: true},
+		"Weak cipher accepted":        {matcher: core.TLSMatcher{Ciphers: []string{"RC4", "3DES"}}, state: downgradable, want: true},
+		"Weak cipher":                 {matcher: core.TLSMatcher{Ciphers: []string{"RC4", "3des"}}, state: state, want: true},
+		"Strong cipher":               {matcher: core.TLSMatcher{Ciphers: []string{"RC4", "3DES"}}, state: expired, want: false},
+		"Weak RSA key":                {matcher: core.TLSMatcher{KeySizeBelow: 2048}, state: state, want: true},
+		"ECDSA key":                   {matcher: core.TLSMatcher{KeySizeBelow: 2048}, state: expired, want: false},
+		"All fields must be met":      {matcher: core.TLSMatcher{SelfSigned: true, Versions: []string{"TLS1.3"}}, state: state, want: false},
+		"Several fields are all met":  {matcher: core.TLSMatcher{SelfSigned: true, Versions: []string{"TLS1.0"}, KeySizeBelow: 2048}, state: state, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := tc.matcher.Match(tc.state, tc.url); have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestTLSMatcherValidate(t *testing.T) {
+	var tests = map[string]struct {
+		yaml  string
+		valid bool
+	}{
+		"Versions":         {yaml: "versions: [TLS1.0, tls1.1]", valid: true},
+		"Expired":          {yaml: "expires_within: 0", valid: true},
+		"Empty":            {yaml: "{}", valid: false},
+		"Unknown version":  {yaml: "versions: [SSLv3]", valid: false},
+		"Negative expires": {yaml: "expires_within: -1", valid: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var matcher core.TLSMatcher
+			if err := yaml.Unmarshal([]byte(tc.yaml), &matcher); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := matcher.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestScanCertificateError(t *testing.T) {
+	expired := &internal.TLSState{Certificates: []*x509.Certificate{selfSignedCert(t, -1, false)}}
+	fetcher := mock.NewFetcher(nil, mock.WithFallback(func(url string) (*internal.HTTPResponse, error) {
+		return nil, &internal.CertificateError{URL: url, TLS: expired, Err: errors.New("x509: certificate has expired")}
+	}))
+	days := 0
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{
+		{ID: "expired", Matchers: core.Matchers{TLS: &core.TLSMatcher{ExpiresWithin: &days}}},
+		{ID: "expired-ok", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200), TLS: &core.TLSMatcher{ExpiresWithin: &days}}},
+		{ID: "not-self-signed", Condition: &core.Condition{Not: &core.Condition{Matchers: core.Matchers{TLS: &core.TLSMatcher{SelfSigned: true}}}}},
+	}}}}
+	output, err := core.NewScanner(fetcher, fetcher, signatures, 1).Scan(context.Background(), []string{"https://site"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output) != 1 || output[0].ID != "expired" || output[0].URL != "https://site/" {
+		t.Errorf("expected only the tls check to match, got: %v", output)
+	}
+}
@@ -3,6 +3,7 @@ module gochopchop
 go 1.15
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef // indirect
 	github.com/go-openapi/errors v0.19.8 // indirect
 	github.com/go-openapi/strfmt v0.19.8 // indirect
@@ -12,7 +13,9 @@ require (
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/afero v1.1.2
 	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
 	go.mongodb.org/mongo-driver v1.4.3 // indirect
 	golang.org/x/sys v0.0.0-20201110211018-35f3e6cf4a65 // indirect
+	golang.org/x/text v0.3.3
 	gopkg.in/yaml.v2 v2.3.0
 )
@@ -1,10 +1,14 @@
 package export
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"gochopchop/core"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -13,11 +17,48 @@ type IFile interface {
 	WriteString(input string) (n int, err error)
 }
 
+// writer adapts an io.Writer to IFile
+type writer struct {
+	io.Writer
+}
+
+func (w writer) WriteString(input string) (int, error) {
+	return io.WriteString(w.Writer, input)
+}
+
+// fileWriter adapts an IFile to io.Writer
+type fileWriter struct {
+	IFile
+}
+
+func (f fileWriter) Write(p []byte) (int, error) {
+	return f.WriteString(string(p))
+}
+
+// WriteCSV writes the output as CSV to w
+func WriteCSV(w io.Writer, out []core.Output) error {
+	return exportCSV(writer{w}, out)
+}
+
+// WriteJSON writes the output as JSON to w
+func WriteJSON(w io.Writer, output []core.Output) error {
+	return exportJSON(writer{w}, output)
+}
+
+// ReadJSON reads the outputs of a JSON export from r
+func ReadJSON(r io.Reader) ([]core.Output, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return importJSON(data)
+}
+
 // ExportCSV exports the output in a CSV file
 func ExportCSV(filename string, out []core.Output) error {
 	exportFilename := fmt.Sprintf("%s.csv", filename)
 
-	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY, 0755)
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
 	defer f.Close()
 	if err != nil {
 		return err
@@ -32,26 +73,49 @@ func ExportCSV(filename string, out []core.Output) error {
 }
 
 func exportCSV(file IFile, out []core.Output) error {
-	_, err := file.WriteString("url,endpoint,severity,checkName,remediation\n")
+	w := csv.NewWriter(fileWriter{file})
+	err := w.Write([]string{"url", "endpoint", "severity", "checkName", "remediation", "id", "tags", "references", "cwe", "cve", "status", "suppression", "justification", "incomplete"})
 	if err != nil {
 		return err
 	}
 	for _, output := range out {
-		line := fmt.Sprintf("%s,%s,%s,%s,%s\n", output.URL, output.Endpoint, output.Severity, output.Name, output.Remediation)
-		_, err := file.WriteString(line)
+		err := w.Write([]string{
+			output.URL,
+			output.Endpoint,
+			output.Severity,
+			output.Name,
+			output.Remediation,
+			output.ID,
+			strings.Join(output.Tags, ";"),
+			strings.Join(output.References, ";"),
+			strings.Join(output.CWE, ";"),
+			strings.Join(output.CVE, ";"),
+			output.Status,
+			output.Suppression,
+			output.Justification,
+			incomplete(output),
+		})
 		if err != nil {
 			return err
 		}
 	}
+	w.Flush()
+	return w.Error()
+}
 
-	return nil
+// incomplete is the CSV value of Output.Incomplete, empty for a complete scan
+func incomplete(output core.Output) string {
+	if output.Incomplete {
+		return "true"
+	}
+	return ""
 }
 
 // ExportJSON will save the output to a JSON file
 func ExportJSON(filename string, output []core.Output) error {
 	exportFilename := fmt.Sprintf("%s.json", filename)
 
-	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY, 0755)
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
 	defer f.Close()
 	if err != nil {
 		return err
@@ -75,3 +139,20 @@ func exportJSON(file IFile, output []core.Output) error {
 	}
 	return nil
 }
+
+// ImportJSON reads the outputs of a previous JSON export
+func ImportJSON(filename string) ([]core.Output, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return importJSON(data)
+}
+
+func importJSON(data []byte) ([]core.Output, error) {
+	var output []core.Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("Invalid JSON export : %v", err)
+	}
+	return output, nil
+}
@@ -17,6 +17,18 @@ func TestExportCSV(t *testing.T) {
 		want   string
 	}{
 		"correct formatting": {output: mock.FakeOutput, want: mock.FakeOutputAsCSV},
+		"suppressed finding": {
+			output: []core.Output{{URL: "http://status", Endpoint: "/", Severity: "Low", Name: "Status", Remediation: "remove", ID: "status", Suppression: core.SuppressionActive, Justification: "public page"}},
+			want:   "url,endpoint,severity,checkName,remediation,id,tags,references,cwe,cve,status,suppression,justification,incomplete\nhttp://status,/,Low,Status,remove,status,,,,,,suppressed,public page,\n",
+		},
+		"incomplete scan": {
+			output: []core.Output{{URL: "http://status", Endpoint: "/", Severity: "Low", Name: "Status", Remediation: "remove", ID: "status", Incomplete: true}},
+			want:   "url,endpoint,severity,checkName,remediation,id,tags,references,cwe,cve,status,suppression,justification,incomplete\nhttp://status,/,Low,Status,remove,status,,,,,,,,true\n",
+		},
+		"field with comma": {
+			output: []core.Output{{URL: "http://status", Endpoint: "/", Severity: "Low", Name: "Status", Remediation: "remove, or \"restrict\" it", ID: "status"}},
+			want:   "url,endpoint,severity,checkName,remediation,id,tags,references,cwe,cve,status,suppression,justification,incomplete\nhttp://status,/,Low,Status,\"remove, or \"\"restrict\"\" it\",status,,,,,,,,\n",
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -52,3 +64,33 @@ func TestExportJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestImportJSON(t *testing.T) {
+	var tests = map[string]struct {
+		data    string
+		output  []core.Output
+		wantErr bool
+	}{
+		"exported output":    {data: mock.FakeOutputAsJSON, output: mock.FakeOutput},
+		"with status":        {data: `[{"url":"http://problems","id":"git","status":"resolved"}]`, output: []core.Output{{URL: "http://problems", ID: "git", Status: "resolved"}}},
+		"incomplete scan":    {data: `[{"url":"http://problems","id":"git","incomplete":true}]`, output: []core.Output{{URL: "http://problems", ID: "git", Incomplete: true}}},
+		"invalid json":       {data: `{"url":`, wantErr: true},
+		"not a finding list": {data: `{"url":"http://problems"}`, wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, err := importJSON([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if len(output) != len(tc.output) {
+				t.Fatalf("want : %v, got : %v", tc.output, output)
+			}
+			for i := range output {
+				if output[i].URL != tc.output[i].URL || output[i].ID != tc.output[i].ID || output[i].Status != tc.output[i].Status {
+					t.Errorf("want : %v, got : %v", tc.output, output)
+				}
+			}
+		})
+	}
+}
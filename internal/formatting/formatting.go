@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gochopchop/core"
 	"io"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/table"
 )
@@ -17,7 +18,21 @@ func PrintTable(outputs []core.Output, mirror io.Writer) {
 	colorCyan := "\033[36m"
 	t := table.NewWriter()
 	t.SetOutputMirror(mirror)
-	t.AppendHeader(table.Row{"URL", "Endpoint", "Severity", "Plugin", "Remediation"})
+	// the status column is only shown when the scan was compared to a baseline
+	withStatus := false
+	incomplete := false
+	for _, output := range outputs {
+		withStatus = withStatus || output.Status != ""
+		incomplete = incomplete || output.Incomplete
+	}
+	if incomplete {
+		t.SetTitle("Incomplete results, the scan was stopped before running every check")
+	}
+	header := table.Row{"URL", "Endpoint", "Severity", "Plugin", "Remediation"}
+	if withStatus {
+		header = append(header, "Status")
+	}
+	t.AppendHeader(header)
 	for _, output := range outputs {
 		severity := ""
 		if output.Severity == "High" {
@@ -29,16 +44,32 @@ func PrintTable(outputs []core.Output, mirror io.Writer) {
 		} else {
 			severity = fmt.Sprint(string(colorCyan), "Informational", string(colorReset))
 		}
-		t.AppendRow([]interface{}{
+		row := table.Row{
 			output.URL,
 			output.Endpoint,
 			severity,
 			output.Name,
 			output.Remediation,
-		})
+		}
+		if withStatus {
+			row = append(row, output.Status)
+		}
+		t.AppendRow(row)
 	}
 	t.SortBy([]table.SortBy{
 		{Name: "Severity", Mode: table.Asc},
 	})
 	t.Render()
 }
+
+// PrintNotRun renders the plugins not run because the time budget of their target ran out
+func PrintNotRun(notRun []core.NotRun, mirror io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(mirror)
+	t.SetTitle("Not run")
+	t.AppendHeader(table.Row{"URL", "Endpoint", "Checks"})
+	for _, job := range notRun {
+		t.AppendRow(table.Row{job.URL, job.Endpoint, strings.Join(job.Checks, ", ")})
+	}
+	t.Render()
+}
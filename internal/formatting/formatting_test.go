@@ -2,8 +2,10 @@ package formatting_test
 
 import (
 	"bytes"
+	"gochopchop/core"
 	"gochopchop/internal/formatting"
 	"gochopchop/mock"
+	"strings"
 	"testing"
 )
 
@@ -17,3 +19,22 @@ func TestFormatOutputTable(t *testing.T) {
 		t.Errorf("want : %q, got : %q", want, got)
 	}
 }
+
+func TestFormatOutputTableWithStatus(t *testing.T) {
+	mirror := new(bytes.Buffer)
+	output := []core.Output{{URL: "http://problems", Endpoint: "/", Severity: "High", Name: "Headers", Status: core.StatusResolved}}
+	formatting.PrintTable(output, mirror)
+	got := mirror.String()
+	if !strings.Contains(got, "| STATUS ") || !strings.Contains(got, "| resolved ") {
+		t.Errorf("expected a status column, got : %q", got)
+	}
+}
+
+func TestFormatOutputTableIncomplete(t *testing.T) {
+	mirror := new(bytes.Buffer)
+	output := []core.Output{{URL: "http://problems", Endpoint: "/", Severity: "High", Name: "Headers", Incomplete: true}}
+	formatting.PrintTable(output, mirror)
+	if got := mirror.String(); !strings.Contains(got, "Incomplete results") {
+		t.Errorf("expected the results to be flagged as incomplete, got : %q", got)
+	}
+}
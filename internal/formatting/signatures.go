@@ -0,0 +1,181 @@
+package formatting
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"gopkg.in/yaml.v2"
+)
+
+// SignatureFormats lists the formats available to print the signatures
+var SignatureFormats = []string{"table", "json", "yaml", "csv", "markdown"}
+
+// CheckEntry is the flattened description of a check and the plugin it belongs to
+type CheckEntry struct {
+	ID              string   `json:"id" yaml:"id"`
+	Name            string   `json:"name" yaml:"name"`
+	Severity        string   `json:"severity" yaml:"severity"`
+	Tags            []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Endpoints       []string `json:"endpoints" yaml:"endpoints"`
+	QueryString     string   `json:"queryString,omitempty" yaml:"query_string,omitempty"`
+	FollowRedirects bool     `json:"followRedirects" yaml:"follow_redirects"`
+	Conditions      []string `json:"conditions" yaml:"conditions"`
+	Description     string   `json:"description" yaml:"description"`
+	Remediation     string   `json:"remediation" yaml:"remediation"`
+	References      []string `json:"references,omitempty" yaml:"references,omitempty"`
+	CWE             []string `json:"cwe,omitempty" yaml:"cwe,omitempty"`
+	CVE             []string `json:"cve,omitempty" yaml:"cve,omitempty"`
+}
+
+// CheckEntries flattens the signatures into one entry per check
+func CheckEntries(signatures *core.Signatures) []CheckEntry {
+	entries := make([]CheckEntry, 0)
+	for _, plugin := range signatures.Plugins {
+		for _, check := range plugin.Checks {
+			entries = append(entries, CheckEntry{
+				ID:              check.ID,
+				Name:            check.Name,
+				Severity:        check.Severity,
+				Tags:            check.Tags,
+				Endpoints:       plugin.GetEndpoints(),
+				QueryString:     plugin.QueryString,
+				FollowRedirects: plugin.FollowRedirects,
+				Conditions:      check.Conditions(),
+				Description:     check.Description,
+				Remediation:     check.Remediation,
+				References:      check.References,
+				CWE:             check.CWE,
+				CVE:             check.CVE,
+			})
+		}
+	}
+	return entries
+}
+
+// ValidSignatureFormat returns true if the signatures can be printed in this format
+func ValidSignatureFormat(format string) bool {
+	for _, f := range SignatureFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintCheckEntries renders the entries in the given format
+func PrintCheckEntries(entries []CheckEntry, format string, mirror io.Writer) error {
+	switch format {
+	case "json":
+		return printJSON(entries, mirror)
+	case "yaml":
+		return printYAML(entries, mirror)
+	case "csv":
+		return printCSV(entries, mirror)
+	case "markdown":
+		_, err := fmt.Fprintln(mirror, entriesTable(entries).RenderMarkdown())
+		return err
+	case "table", "":
+		t := entriesTable(entries)
+		t.SetOutputMirror(mirror)
+		t.AppendFooter(table.Row{"", "", "Total Checks", len(entries)})
+		t.Render()
+		return nil
+	}
+	return fmt.Errorf("unknown format %s, expected one of %s", format, strings.Join(SignatureFormats, ", "))
+}
+
+// PrintCheckDetails renders every field of the entries, one entry after the other
+func PrintCheckDetails(entries []CheckEntry, mirror io.Writer) {
+	for _, entry := range entries {
+		t := table.NewWriter()
+		t.SetOutputMirror(mirror)
+		t.AppendRows([]table.Row{
+			{"ID", entry.ID},
+			{"Name", entry.Name},
+			{"Severity", entry.Severity},
+			{"Tags", strings.Join(entry.Tags, ", ")},
+			{"Endpoints", strings.Join(entry.Endpoints, "\n")},
+			{"Query String", entry.QueryString},
+			{"Follow Redirects", entry.FollowRedirects},
+			{"Conditions", strings.Join(entry.Conditions, "\n")},
+			{"Description", entry.Description},
+			{"Remediation", entry.Remediation},
+			{"References", strings.Join(entry.References, "\n")},
+			{"CWE", strings.Join(entry.CWE, ", ")},
+			{"CVE", strings.Join(entry.CVE, ", ")},
+		})
+		t.Render()
+	}
+}
+
+func entriesTable(entries []CheckEntry) table.Writer {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"ID", "Plugin Name", "Severity", "Tags", "Endpoints", "Query String", "Conditions", "Description"})
+	for _, entry := range entries {
+		t.AppendRow([]interface{}{
+			entry.ID,
+			entry.Name,
+			entry.Severity,
+			strings.Join(entry.Tags, ", "),
+			strings.Join(entry.Endpoints, "\n"),
+			entry.QueryString,
+			strings.Join(entry.Conditions, "\n"),
+			entry.Description,
+		})
+	}
+	return t
+}
+
+func printJSON(entries []CheckEntry, mirror io.Writer) error {
+	jsonbytes, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(mirror, string(jsonbytes))
+	return err
+}
+
+func printYAML(entries []CheckEntry, mirror io.Writer) error {
+	yamlbytes, err := yaml.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	_, err = mirror.Write(yamlbytes)
+	return err
+}
+
+func printCSV(entries []CheckEntry, mirror io.Writer) error {
+	w := csv.NewWriter(mirror)
+	err := w.Write([]string{"id", "name", "severity", "tags", "endpoints", "queryString", "followRedirects", "conditions", "description", "remediation", "references", "cwe", "cve"})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := w.Write([]string{
+			entry.ID,
+			entry.Name,
+			entry.Severity,
+			strings.Join(entry.Tags, ";"),
+			strings.Join(entry.Endpoints, ";"),
+			entry.QueryString,
+			strconv.FormatBool(entry.FollowRedirects),
+			strings.Join(entry.Conditions, ";"),
+			entry.Description,
+			entry.Remediation,
+			strings.Join(entry.References, ";"),
+			strings.Join(entry.CWE, ";"),
+			strings.Join(entry.CVE, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,68 @@
+package formatting_test
+
+import (
+	"bytes"
+	"gochopchop/core"
+	"gochopchop/internal/formatting"
+	"gochopchop/mock"
+	"testing"
+)
+
+func TestCheckEntries(t *testing.T) {
+	signatures := &core.Signatures{Plugins: []*core.Plugin{
+		{Endpoints: []string{"/a", "/b"}, QueryString: "query=test", Checks: []*core.Check{mock.FakeCheckStatusCode200}},
+	}}
+	entries := formatting.CheckEntries(signatures)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got: %v", entries)
+	}
+	entry := entries[0]
+	if !core.SliceStringEqual(entry.Endpoints, []string{"/a", "/b"}) {
+		t.Errorf("expected both endpoints, got: %v", entry.Endpoints)
+	}
+	if entry.QueryString != "query=test" {
+		t.Errorf("expected query string, got: %v", entry.QueryString)
+	}
+	if !core.SliceStringEqual(entry.Conditions, []string{"status_code: 200"}) {
+		t.Errorf("expected status code condition, got: %v", entry.Conditions)
+	}
+}
+
+func TestPrintCheckEntries(t *testing.T) {
+	entries := formatting.CheckEntries(&core.Signatures{Plugins: []*core.Plugin{mock.FakeQueryPlugin}})
+
+	var tests = map[string]struct {
+		format string
+		want   string
+		err    bool
+	}{
+		"csv": {
+			format: "csv",
+			want:   "id,name,severity,tags,endpoints,queryString,followRedirects,conditions,description,remediation,references,cwe,cve\nstatus-code-200,StatusCode200,Medium,exposure,/,query=test,false,status_code: 200,,uninstall,,,\n",
+		},
+		"markdown": {
+			format: "markdown",
+			want:   "| ID | Plugin Name | Severity | Tags | Endpoints | Query String | Conditions | Description |\n| --- | --- | --- | --- | --- | --- | --- | --- |\n| status-code-200 | StatusCode200 | Medium | exposure | / | query=test | status_code: 200 |  |\n",
+		},
+		"unknown format": {format: "xml", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mirror := new(bytes.Buffer)
+			err := formatting.PrintCheckEntries(entries, tc.format, mirror)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := mirror.String(); got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
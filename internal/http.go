@@ -1,9 +1,73 @@
 package internal
 
-import "net/http"
+import (
+	"crypto/x509"
+	"net/http"
+)
 
 type HTTPResponse struct {
 	StatusCode int
 	Body       string
+	// RawBody holds the bytes of the body before their conversion to UTF-8, empty when Body didn't need it
+	RawBody string
+	// Truncated is true when only the beginning of the body was read, because of the size limit
+	// or because the checks didn't need the rest of it
+	Truncated bool
+	Header    http.Header
+	// TLS is the TLS configuration of the server the response was received from, nil without TLS
+	TLS *TLSState
+	// URL is the URL of the response, the last one of the redirect chain
+	URL string
+	// Redirects are the redirect responses received, in order, including a final one that wasn't followed
+	Redirects []Redirect
+}
+
+// TLSState describes the TLS configuration of a server, probed apart from the requests
+type TLSState struct {
+	// ServerName is the name the server was asked for in the handshake
+	ServerName string
+	// Certificates are the certificates sent by the server, the leaf first, whether they could be verified or not
+	Certificates []*x509.Certificate
+	// Versions are the protocol versions the server accepted
+	Versions []uint16
+	// CipherSuites are the cipher suites the server accepted
+	CipherSuites []uint16
+}
+
+// CertificateError is returned when a request fails because the certificate of the server couldn't be verified,
+// along with the TLS configuration of the server
+type CertificateError struct {
+	URL string
+	TLS *TLSState
+	Err error
+}
+
+func (e *CertificateError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CertificateError) Unwrap() error {
+	return e.Err
+}
+
+// Redirect is a redirect response of the chain
+type Redirect struct {
+	URL        string
+	StatusCode int
 	Header     http.Header
+	// Location is the absolute URL the response redirects to
+	Location string
+}
+
+// Raw returns the body as received, decompressed but not converted to UTF-8
+func (r *HTTPResponse) Raw() string {
+	if r.RawBody == "" {
+		return r.Body
+	}
+	return r.RawBody
+}
+
+// Cookies parses the Set-Cookie headers of the response
+func (r *HTTPResponse) Cookies() []*http.Cookie {
+	return (&http.Response{Header: r.Header}).Cookies()
 }
@@ -0,0 +1,107 @@
+package httpget
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// acceptEncoding lists the content encodings the fetcher can decode
+const acceptEncoding = "gzip, deflate, br"
+
+// encodingTransport asks for compressed responses, the fetcher decoding them instead of the http package
+type encodingTransport struct {
+	http.RoundTripper
+}
+
+func (t encodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// decompress decodes the body according to the Content-Encoding header, encodings being applied in order.
+// A body with an unknown encoding, often a misconfigured header, is returned as received.
+func decompress(body io.Reader, contentEncoding string) (io.Reader, error) {
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var decoded io.Reader
+		var err error
+		switch strings.ToLower(strings.TrimSpace(encodings[i])) {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			decoded, err = gzip.NewReader(body)
+		case "deflate":
+			decoded, err = inflate(body)
+		case "br":
+			decoded = brotli.NewReader(body)
+		default:
+			return body, nil
+		}
+		if err == io.EOF {
+			// the compressed body of redirect and not modified responses is often empty
+			return strings.NewReader(""), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+	}
+	return body, nil
+}
+
+// inflate reads deflate bodies, sent either with the zlib wrapper the RFC requires or without it
+func inflate(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return buffered, nil
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([a-z0-9_:.-]+)`)
+
+// charsetDecoder returns the decoder converting a text body to UTF-8,
+// nil when the body isn't text or is already UTF-8.
+// The charset is read from the Content-Type header, or from the meta tags at the start of HTML pages.
+func charsetDecoder(contentType string, head []byte) *encoding.Decoder {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !isText(mediaType) {
+		return nil
+	}
+	name := params["charset"]
+	if name == "" && mediaType == "text/html" {
+		if match := metaCharset.FindSubmatch(head); match != nil {
+			name = string(match[1])
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil || enc == unicode.UTF8 {
+		return nil
+	}
+	return enc.NewDecoder()
+}
+
+func isText(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/json" || mediaType == "application/javascript" || mediaType == "application/xml"
+}
@@ -1,10 +1,17 @@
 package httpget
 
 import (
+	"bufio"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"gochopchop/internal"
-	"io/ioutil"
+	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -12,6 +19,11 @@ type IHTTPClient interface {
 	Get(url string) (*http.Response, error)
 }
 
+// requestDoer is implemented by the clients able to send a request bound to a context, like http.Client
+type requestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 type HTTPClient struct {
 	Transport http.RoundTripper
 	Timeout   time.Duration
@@ -19,58 +31,207 @@ type HTTPClient struct {
 
 type Fetcher struct {
 	Netclient IHTTPClient
+	// MaxBodySize is the number of bytes of the body read at most, 0 meaning no limit
+	MaxBodySize int64
+	// tlsProber probes the TLS configuration of the servers when set, the one of the connections being used otherwise
+	tlsProber *tlsProber
 }
 
-func NewFetcher(insecure bool, timeout int) *Fetcher {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+// chunkSize is the number of bytes read between two calls of the done function of FetchUntil
+const chunkSize = 32 * 1024
+
+// newTransport returns the transport of the fetchers, going through proxy when set and adding headers to every request
+func newTransport(insecure bool, proxy *url.URL, headers http.Header) http.RoundTripper {
+	tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure}}
+	if proxy != nil {
+		tr.Proxy = http.ProxyURL(proxy)
 	}
+	var transport http.RoundTripper = encodingTransport{tr}
+	if len(headers) > 0 {
+		transport = headerTransport{transport, headers}
+	}
+	return transport
+}
+
+// headerTransport sets custom headers on the requests, the Host header replacing the host of the request
+type headerTransport struct {
+	http.RoundTripper
+	header http.Header
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.header {
+		if http.CanonicalHeaderKey(name) == "Host" {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func NewFetcher(insecure bool, timeout int, maxBodySize int64, proxy *url.URL, headers http.Header) *Fetcher {
 	var netClient = &http.Client{
-		Transport: tr,
+		Transport: newTransport(insecure, proxy, headers),
 		Timeout:   time.Second * time.Duration(timeout),
 	}
 	return &Fetcher{
-		Netclient: netClient,
+		Netclient:   netClient,
+		MaxBodySize: maxBodySize,
+		tlsProber:   newTLSProber(proxy, netClient.Timeout),
 	}
 }
 
-func NewNoRedirectFetcher(insecure bool, timeout int) *Fetcher {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
+func NewNoRedirectFetcher(insecure bool, timeout int, maxBodySize int64, proxy *url.URL, headers http.Header) *Fetcher {
 	var netClient = &http.Client{
-		Transport: tr,
+		Transport: newTransport(insecure, proxy, headers),
 		Timeout:   time.Second * time.Duration(timeout),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 	return &Fetcher{
-		Netclient: netClient,
+		Netclient:   netClient,
+		MaxBodySize: maxBodySize,
+		tlsProber:   newTLSProber(proxy, netClient.Timeout),
 	}
 }
 
 func (s Fetcher) Fetch(url string) (*internal.HTTPResponse, error) {
+	return s.FetchUntil(context.Background(), url, nil)
+}
+
+// FetchUntil fetches the url, reading the body until done returns true or MaxBodySize is reached.
+// done is called with the response holding the body read so far, the response being marked as truncated
+// when the whole body hasn't been read. The request is aborted once ctx is done, if the client supports it.
+// A request failing because of the certificate of the server returns a CertificateError.
+func (s Fetcher) FetchUntil(ctx context.Context, url string, done func(resp *internal.HTTPResponse) bool) (*internal.HTTPResponse, error) {
 
-	resp, err := s.Netclient.Get(url)
+	resp, err := s.get(ctx, url)
 	if err != nil {
+		if state := s.tlsState(url, nil); state != nil && isCertificateError(err) {
+			return nil, &internal.CertificateError{URL: url, TLS: state, Err: err}
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	var r = &internal.HTTPResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Redirects:  redirectChain(resp),
+	}
+	if resp.Request != nil {
+		r.URL = resp.Request.URL.String()
+	}
+	r.TLS = s.tlsState(r.URL, resp.TLS)
+
+	content, err := decompress(resp.Body, resp.Header.Get("Content-Encoding"))
 	if err != nil {
 		return nil, err
 	}
-	bodyString := string(bodyBytes)
+	limited := &io.LimitedReader{R: content, N: s.MaxBodySize}
+	if s.MaxBodySize <= 0 {
+		limited.N = math.MaxInt64
+	}
+	buffered := bufio.NewReaderSize(limited, chunkSize)
+	head, _ := buffered.Peek(1024)
 
-	var r = &internal.HTTPResponse{
-		Body:       bodyString,
-		StatusCode: resp.StatusCode,
-		Header:     resp.Header,
+	// the raw body is only kept apart when the text has to be converted to UTF-8
+	var raw strings.Builder
+	var text io.Reader = buffered
+	if decoder := charsetDecoder(resp.Header.Get("Content-Type"), head); decoder != nil {
+		text = decoder.Reader(io.TeeReader(buffered, &raw))
+	}
+
+	var body strings.Builder
+	chunk := make([]byte, chunkSize)
+	for {
+		n, err := text.Read(chunk)
+		body.Write(chunk[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if done != nil && n > 0 {
+			r.Body, r.RawBody = body.String(), raw.String()
+			if done(r) {
+				// the body is only truncated if the reading stops before its end
+				if n, _ := io.ReadFull(text, chunk[:1]); n > 0 {
+					r.Truncated = true
+				}
+				break
+			}
+		}
+	}
+	r.Body, r.RawBody = body.String(), raw.String()
+	if limited.N == 0 {
+		// the limit is reached, the body is truncated unless it ends right there
+		if n, _ := content.Read(chunk[:1]); n > 0 {
+			r.Truncated = true
+		}
 	}
 
-	return r, err
+	return r, nil
+}
+
+// tlsState returns the TLS configuration of the server of the url, or the one of the connection
+// when the server couldn't be probed
+func (s Fetcher) tlsState(url string, cs *tls.ConnectionState) *internal.TLSState {
+	if s.tlsProber != nil {
+		if state := s.tlsProber.state(url); state != nil {
+			return state
+		}
+	}
+	return connectionTLSState(cs)
+}
+
+// isCertificateError returns true if err comes from the verification of the certificate of the server
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname)
+}
+
+func (s Fetcher) get(ctx context.Context, url string) (*http.Response, error) {
+	client, ok := s.Netclient.(requestDoer)
+	if !ok {
+		return s.Netclient.Get(url)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// redirectChain lists the redirects followed to get the response, and the response itself when it is a redirect
+func redirectChain(resp *http.Response) []internal.Redirect {
+	var chain []internal.Redirect
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirect := internal.Redirect{
+			StatusCode: req.Response.StatusCode,
+			Header:     req.Response.Header,
+			Location:   req.URL.String(),
+		}
+		if req.Response.Request != nil {
+			redirect.URL = req.Response.Request.URL.String()
+		}
+		chain = append([]internal.Redirect{redirect}, chain...)
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Request != nil {
+		if location, err := resp.Location(); err == nil {
+			chain = append(chain, internal.Redirect{
+				URL:        resp.Request.URL.String(),
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Location:   location.String(),
+			})
+		}
+	}
+	return chain
 }
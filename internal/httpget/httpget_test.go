@@ -1,9 +1,26 @@
 package httpget_test
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"gochopchop/internal"
+	"gochopchop/internal/httpget"
 	"gochopchop/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
 )
 
 func TestFetch(t *testing.T) {
@@ -28,3 +45,253 @@ func TestFetch(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchRedirectChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin":
+			http.Redirect(w, r, "/auth", http.StatusMovedPermanently)
+		case "/auth":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		default:
+			fmt.Fprint(w, "login")
+		}
+	}))
+	defer server.Close()
+
+	var tests = map[string]struct {
+		fetcher   *httpget.Fetcher
+		path      string
+		final     string
+		locations []string
+		codes     []int
+	}{
+		"Followed redirects":  {fetcher: httpget.NewFetcher(false, 5, 0, nil, nil), path: "/admin", final: "/login", locations: []string{"/auth", "/login"}, codes: []int{301, 302}},
+		"Unfollowed redirect": {fetcher: httpget.NewNoRedirectFetcher(false, 5, 0, nil, nil), path: "/admin", final: "/admin", locations: []string{"/auth"}, codes: []int{301}},
+		"No redirect":         {fetcher: httpget.NewFetcher(false, 5, 0, nil, nil), path: "/login", final: "/login"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := tc.fetcher.Fetch(server.URL + tc.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Redirects) != len(tc.locations) {
+				t.Fatalf("expected %d redirects, got: %v", len(tc.locations), resp.Redirects)
+			}
+			for i, redirect := range resp.Redirects {
+				if redirect.Location != server.URL+tc.locations[i] || redirect.StatusCode != tc.codes[i] {
+					t.Errorf("expected redirect %d to %s with %d, got: %v", i, tc.locations[i], tc.codes[i], redirect)
+				}
+			}
+			if resp.URL != server.URL+tc.final {
+				t.Errorf("expected URL %s, got: %s", tc.final, resp.URL)
+			}
+		})
+	}
+}
+
+func TestFetchUntil(t *testing.T) {
+	body := strings.Repeat("a", 100*1024) + "END"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	var tests = map[string]struct {
+		maxBodySize int64
+		done        func(resp *internal.HTTPResponse) bool
+		length      int
+		truncated   bool
+	}{
+		"Whole body":          {length: len(body)},
+		"Never done":          {done: func(resp *internal.HTTPResponse) bool { return false }, length: len(body)},
+		"Size limit":          {maxBodySize: 1000, length: 1000, truncated: true},
+		"Limit over the size": {maxBodySize: int64(len(body)) + 1, length: len(body)},
+		"Done early":          {done: func(resp *internal.HTTPResponse) bool { return len(resp.Body) > 0 }, truncated: true},
+		"Done at the end":     {done: func(resp *internal.HTTPResponse) bool { return strings.HasSuffix(resp.Body, "END") }, length: len(body)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := httpget.NewFetcher(false, 5, tc.maxBodySize, nil, nil)
+			resp, err := fetcher.FetchUntil(context.Background(), server.URL, tc.done)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Truncated != tc.truncated {
+				t.Errorf("expected truncated to be %v, got: %v", tc.truncated, resp.Truncated)
+			}
+			if tc.length > 0 && len(resp.Body) != tc.length {
+				t.Errorf("expected a body of %d bytes, got: %d", tc.length, len(resp.Body))
+			}
+			if tc.truncated && len(resp.Body) >= len(body) {
+				t.Errorf("expected a partial body, got %d bytes", len(resp.Body))
+			}
+		})
+	}
+}
+
+func TestFetchDecodesBody(t *testing.T) {
+	compress := func(encoding string, data string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		case "br":
+			w = brotli.NewWriter(&buf)
+		}
+		w.Write([]byte(data))
+		w.Close()
+		return buf.Bytes()
+	}
+	var tests = map[string]struct {
+		header http.Header
+		body   []byte
+		want   string
+		raw    string
+	}{
+		"Plain":          {header: http.Header{"Content-Type": {"text/html"}}, body: []byte("héllo"), want: "héllo"},
+		"Gzip":           {header: http.Header{"Content-Encoding": {"gzip"}}, body: compress("gzip", "hello gzip"), want: "hello gzip"},
+		"Deflate":        {header: http.Header{"Content-Encoding": {"deflate"}}, body: compress("deflate", "hello deflate"), want: "hello deflate"},
+		"Raw deflate":    {header: http.Header{"Content-Encoding": {"deflate"}}, body: compress("raw-deflate", "hello deflate"), want: "hello deflate"},
+		"Brotli":         {header: http.Header{"Content-Encoding": {"br"}}, body: compress("br", "hello brotli"), want: "hello brotli"},
+		"Latin-1 header": {header: http.Header{"Content-Type": {"text/html; charset=ISO-8859-1"}}, body: []byte("caf\xe9"), want: "café", raw: "caf\xe9"},
+		"Meta charset":   {header: http.Header{"Content-Type": {"text/html"}}, body: []byte(`<meta charset="windows-1252"><p>caf` + "\xe9"), want: `<meta charset="windows-1252"><p>café`, raw: `<meta charset="windows-1252"><p>caf` + "\xe9"},
+		"Unknown":        {header: http.Header{"Content-Encoding": {"UTF-8"}}, body: []byte("hello"), want: "hello"},
+		"Empty gzip":     {header: http.Header{"Content-Encoding": {"gzip"}}, body: nil, want: ""},
+		"Empty deflate":  {header: http.Header{"Content-Encoding": {"deflate"}}, body: nil, want: ""},
+		"Binary":         {header: http.Header{"Content-Type": {"application/octet-stream; charset=ISO-8859-1"}}, body: []byte("PK\x03\x04\xe9"), want: "PK\x03\x04\xe9"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept-Encoding") != "gzip, deflate, br" {
+					t.Errorf("unexpected Accept-Encoding: %s", r.Header.Get("Accept-Encoding"))
+				}
+				for k, v := range tc.header {
+					w.Header()[k] = v
+				}
+				w.Write(tc.body)
+			}))
+			defer server.Close()
+
+			resp, err := httpget.NewFetcher(false, 5, 0, nil, nil).Fetch(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Body != tc.want {
+				t.Errorf("expected body: %q, got: %q", tc.want, resp.Body)
+			}
+			if resp.RawBody != tc.raw {
+				t.Errorf("expected raw body: %q, got: %q", tc.raw, resp.RawBody)
+			}
+		})
+	}
+}
+
+func TestFetchThroughProxyWithHeaders(t *testing.T) {
+	var received *http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	headers := http.Header{"Authorization": {"Bearer token"}, "X-Scanner": {"chopchop"}, "Host": {"vhost.local"}}
+	resp, err := httpget.NewFetcher(false, 5, 0, proxyURL, headers).Fetch("http://target.invalid/admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != "proxied" {
+		t.Errorf("expected the response of the proxy, got: %q", resp.Body)
+	}
+	if !received.URL.IsAbs() || received.URL.Path != "/admin" {
+		t.Errorf("expected the proxy to get the absolute target URL, got: %s", received.URL)
+	}
+	if received.Host != "vhost.local" {
+		t.Errorf("expected host: vhost.local, got: %s", received.Host)
+	}
+	if received.Header.Get("Authorization") != "Bearer token" || received.Header.Get("X-Scanner") != "chopchop" {
+		t.Errorf("expected custom headers, got: %v", received.Header)
+	}
+	if received.Header.Get("Accept-Encoding") != "gzip, deflate, br" {
+		t.Errorf("expected Accept-Encoding to be kept, got: %v", received.Header)
+	}
+}
+
+func TestFetchUntilCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	_, err := httpget.NewFetcher(false, 10, 0, nil, nil).FetchUntil(ctx, server.URL, nil)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("expected the request to be aborted, took %s", elapsed)
+	}
+}
+
+func TestFetchProbesTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, tls.VersionName(r.TLS.Version))
+	}))
+	server.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS10,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	resp, err := httpget.NewFetcher(true, 5, 0, nil, nil).Fetch(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != "TLS 1.3" {
+		t.Errorf("expected the request to use the default TLS configuration, got: %s", resp.Body)
+	}
+	if resp.TLS == nil || len(resp.TLS.Certificates) == 0 {
+		t.Fatalf("expected the certificate of the server, got: %v", resp.TLS)
+	}
+	if !hasID(resp.TLS.Versions, tls.VersionTLS10) || !hasID(resp.TLS.Versions, tls.VersionTLS11) {
+		t.Errorf("expected the deprecated versions to be accepted, got: %v", resp.TLS.Versions)
+	}
+	if !hasID(resp.TLS.CipherSuites, tls.TLS_RSA_WITH_AES_128_CBC_SHA) {
+		t.Errorf("expected the weak cipher suite to be accepted, got: %v", resp.TLS.CipherSuites)
+	}
+
+	_, err = httpget.NewFetcher(false, 5, 0, nil, nil).Fetch(server.URL)
+	var certErr *internal.CertificateError
+	if !errors.As(err, &certErr) {
+		t.Fatalf("expected a certificate error, got: %v", err)
+	}
+	if certErr.TLS == nil || len(certErr.TLS.Certificates) == 0 {
+		t.Errorf("expected the certificate of the server, got: %v", certErr.TLS)
+	}
+}
+
+func hasID(ids []uint16, id uint16) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
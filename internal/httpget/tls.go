@@ -0,0 +1,155 @@
+package httpget
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"gochopchop/internal"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errProbed aborts the probe handshakes once the connection state is recorded, so that no request is sent
+var errProbed = errors.New("TLS probe done")
+
+// deprecatedVersions are the protocol versions the servers are probed for
+var deprecatedVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11}
+
+// tlsProber probes the TLS configuration of the servers, once per host. The requests don't go through it
+// and keep the default TLS configuration, the probe handshakes being aborted before anything is sent.
+type tlsProber struct {
+	proxy   *url.URL
+	timeout time.Duration
+	mux     sync.Mutex
+	hosts   map[string]*tlsProbe
+}
+
+type tlsProbe struct {
+	once  sync.Once
+	state *internal.TLSState
+}
+
+func newTLSProber(proxy *url.URL, timeout time.Duration) *tlsProber {
+	return &tlsProber{proxy: proxy, timeout: timeout, hosts: make(map[string]*tlsProbe)}
+}
+
+// state returns the TLS configuration of the server of an https url, nil if it couldn't be probed
+func (p *tlsProber) state(rawURL string) *internal.TLSState {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	p.mux.Lock()
+	probe, ok := p.hosts[u.Host]
+	if !ok {
+		probe = &tlsProbe{}
+		p.hosts[u.Host] = probe
+	}
+	p.mux.Unlock()
+	probe.once.Do(func() {
+		probe.state = p.probe(u)
+	})
+	return probe.state
+}
+
+// probe records the certificates of the server, then checks whether it accepts deprecated protocol versions
+// and weak cipher suites
+func (p *tlsProber) probe(u *url.URL) *internal.TLSState {
+	state := connectionTLSState(p.handshake(u, &tls.Config{}))
+	if state == nil {
+		return nil
+	}
+	var configs []*tls.Config
+	for _, version := range deprecatedVersions {
+		configs = append(configs, &tls.Config{MinVersion: version, MaxVersion: version, CipherSuites: allCipherSuites()})
+	}
+	// the cipher suites of TLS 1.3 can't be chosen, they are all considered secure
+	configs = append(configs, &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS12, CipherSuites: weakCipherSuites()})
+	for _, config := range configs {
+		if cs := p.handshake(u, config); cs != nil {
+			state.Versions = appendUnique(state.Versions, cs.Version)
+			state.CipherSuites = appendUnique(state.CipherSuites, cs.CipherSuite)
+		}
+	}
+	return state
+}
+
+// handshake returns the state of a TLS handshake with the server of u, nil if the server refused it.
+// The certificate is read without being verified and the connection closed before any request is sent.
+func (p *tlsProber) handshake(u *url.URL, config *tls.Config) *tls.ConnectionState {
+	var mux sync.Mutex
+	var state *tls.ConnectionState
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		mux.Lock()
+		defer mux.Unlock()
+		state = &cs
+		return errProbed
+	}
+	tr := &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}
+	if p.proxy != nil {
+		tr.Proxy = http.ProxyURL(p.proxy)
+	}
+	defer tr.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}).String(), nil)
+	if err != nil {
+		return nil
+	}
+	if resp, err := tr.RoundTrip(req); err == nil {
+		resp.Body.Close()
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	return state
+}
+
+// allCipherSuites are the cipher suites implemented, so that a protocol version isn't refused for the lack of one
+func allCipherSuites() []uint16 {
+	var ids []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		ids = append(ids, suite.ID)
+	}
+	return ids
+}
+
+// weakCipherSuites are the insecure cipher suites and the ones without forward secrecy
+func weakCipherSuites() []uint16 {
+	var ids []uint16
+	for _, suite := range tls.InsecureCipherSuites() {
+		ids = append(ids, suite.ID)
+	}
+	for _, suite := range tls.CipherSuites() {
+		if strings.HasPrefix(suite.Name, "TLS_RSA_") {
+			ids = append(ids, suite.ID)
+		}
+	}
+	return ids
+}
+
+// connectionTLSState describes the TLS configuration of a server from the connection a response was received on
+func connectionTLSState(cs *tls.ConnectionState) *internal.TLSState {
+	if cs == nil {
+		return nil
+	}
+	return &internal.TLSState{
+		ServerName:   cs.ServerName,
+		Certificates: cs.PeerCertificates,
+		Versions:     []uint16{cs.Version},
+		CipherSuites: []uint16{cs.CipherSuite},
+	}
+}
+
+func appendUnique(ids []uint16, id uint16) []uint16 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/export"
+	"gochopchop/internal/formatting"
+	"io"
+	"net/http"
+	"time"
+)
+
+// IEmitter is an output the changes found by the monitor are emitted to
+type IEmitter interface {
+	Emit(at time.Time, changes []core.Output) error
+}
+
+// TableEmitter prints the changes as a table
+type TableEmitter struct {
+	Writer io.Writer
+}
+
+func (e TableEmitter) Emit(at time.Time, changes []core.Output) error {
+	if _, err := fmt.Fprintf(e.Writer, "Changes found by the scan of %s\n", at.Format(time.RFC3339)); err != nil {
+		return err
+	}
+	formatting.PrintTable(changes, e.Writer)
+	return nil
+}
+
+// ExportEmitter exports the changes of each scan to files named after the filename and the time of the scan
+type ExportEmitter struct {
+	Formats  []string
+	Filename string
+}
+
+func (e ExportEmitter) Emit(at time.Time, changes []core.Output) error {
+	filename := fmt.Sprintf("%s_%s", e.Filename, at.UTC().Format(historyTimeFormat))
+	for _, format := range e.Formats {
+		var err error
+		switch format {
+		case "json":
+			err = export.ExportJSON(filename, changes)
+		case "csv":
+			err = export.ExportCSV(filename, changes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookEmitter posts the changes as JSON to a URL
+type WebhookEmitter struct {
+	URL    string
+	Client *http.Client
+}
+
+// webhookPayload is the body posted by the webhook emitter
+type webhookPayload struct {
+	Time    time.Time     `json:"time"`
+	Changes []core.Output `json:"changes"`
+}
+
+func (e WebhookEmitter) Emit(at time.Time, changes []core.Output) error {
+	body, err := json.Marshal(webhookPayload{Time: at, Changes: changes})
+	if err != nil {
+		return err
+	}
+	resp, err := e.Client.Post(e.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook %s answered with status %d", e.URL, resp.StatusCode)
+	}
+	return nil
+}
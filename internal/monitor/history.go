@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal/export"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyTimeFormat names the files of the history, in UTC so that they sort in time order
+const historyTimeFormat = "2006-01-02T15-04-05.000Z"
+
+// History stores the findings of every scan of the monitor in a directory, one JSON export per scan
+type History struct {
+	dir string
+	// keep is the number of scans kept, the oldest being deleted first, 0 to keep them all
+	keep int
+}
+
+// NewHistory returns the history stored in dir, created if needed
+func NewHistory(dir string, keep int) (*History, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &History{dir: dir, keep: keep}, nil
+}
+
+// files returns the files of the history, the oldest first
+func (h *History) files() ([]string, error) {
+	entries, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(h.dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Latest returns the findings of the last scan, nil before the first one
+func (h *History) Latest() ([]core.Output, error) {
+	files, err := h.files()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	return export.ImportJSON(files[len(files)-1])
+}
+
+// Save stores the findings of the scan run at the time, then deletes the scans over the number kept
+func (h *History) Save(at time.Time, outputs []core.Output) error {
+	tmp, err := ioutil.TempFile(h.dir, ".scan-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := export.WriteJSON(tmp, outputs); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(h.dir, at.UTC().Format(historyTimeFormat)+".json")); err != nil {
+		return err
+	}
+
+	files, err := h.files()
+	if err != nil {
+		return err
+	}
+	for h.keep > 0 && len(files) > h.keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
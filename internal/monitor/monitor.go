@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/schedule"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Monitor scans the same urls again and again, emitting only the findings new or resolved since the previous scan
+type Monitor struct {
+	Scanner *core.Scanner
+	Urls    []string
+	History *History
+	// Suppressions hide the changes of the findings accepted as risks when set
+	Suppressions *core.Suppressions
+	Emitters     []IEmitter
+	// MaxDuration bounds each scan, 0 for no limit
+	MaxDuration time.Duration
+}
+
+// Run scans right away, then at every time of the schedule until ctx is done.
+// A failed scan is logged and the monitor waits for the next one.
+func (m *Monitor) Run(ctx context.Context, s schedule.Schedule) error {
+	for {
+		if _, err := m.Scan(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error("Monitoring scan failed : ", err)
+		}
+		next := s.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("The schedule has no next run")
+		}
+		log.Info("Next monitoring scan at ", next.Format(time.RFC3339))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Scan runs one scan, stores its findings in the history and emits the changes since the previous scan.
+// An interrupted scan is neither stored nor emitted since its missing findings would be reported as resolved.
+func (m *Monitor) Scan(ctx context.Context) ([]core.Output, error) {
+	at := time.Now()
+	previous, err := m.History.Latest()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the history : %v", err)
+	}
+
+	scanCtx := ctx
+	if m.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, m.MaxDuration)
+		defer cancel()
+	}
+	outputs, err := m.Scanner.Scan(scanCtx, m.Urls)
+	var notRun *core.NotRunError
+	if errors.As(err, &notRun) {
+		log.Warn(notRun)
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("Scan stopped by the max duration of %s, the results are incomplete", m.MaxDuration)
+	} else if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool)
+	if notRun != nil {
+		for _, job := range notRun.NotRun {
+			skipped[job.URL] = true
+		}
+	}
+	var snapshot, changes []core.Output
+	for _, output := range core.CompareBaseline(outputs, previous) {
+		// the findings of the plugins not run can't be told resolved, they are kept as they were
+		unverified := output.Status == core.StatusResolved && skipped[output.URL]
+		if output.Status != core.StatusResolved || unverified {
+			stored := output
+			stored.Status = ""
+			snapshot = append(snapshot, stored)
+		}
+		if output.Status != core.StatusPresent && !unverified {
+			changes = append(changes, output)
+		}
+	}
+	if snapshot == nil {
+		snapshot = make([]core.Output, 0)
+	}
+	if err := m.History.Save(at, snapshot); err != nil {
+		return nil, fmt.Errorf("Could not save the history : %v", err)
+	}
+
+	if m.Suppressions != nil {
+		var visible []core.Output
+		for _, output := range m.Suppressions.Apply(changes, at) {
+			if output.Suppression != core.SuppressionActive {
+				visible = append(visible, output)
+			}
+		}
+		changes = visible
+	}
+	log.Infof("Monitoring scan found %d findings, %d changes", len(outputs), len(changes))
+	if len(changes) == 0 {
+		return changes, nil
+	}
+	for _, emitter := range m.Emitters {
+		if err := emitter.Emit(at, changes); err != nil {
+			log.Error("Could not emit the changes : ", err)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,182 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/internal/monitor"
+	"gochopchop/mock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// expose answers 200 to the urls, the others getting a 404
+func expose(fetcher *mock.Fetcher, urls ...string) {
+	responses := mock.FakeFetcherWithoutNetclient{}
+	for _, url := range urls {
+		responses[url] = &internal.HTTPResponse{StatusCode: 200}
+	}
+	fetcher.SetResponses(responses)
+}
+
+// recordingEmitter records the changes emitted
+type recordingEmitter struct {
+	mux     sync.Mutex
+	emitted [][]core.Output
+}
+
+func (e *recordingEmitter) Emit(at time.Time, changes []core.Output) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.emitted = append(e.emitted, changes)
+	return nil
+}
+
+func newMonitor(t *testing.T, keep int) (*monitor.Monitor, *mock.Fetcher, *recordingEmitter, string) {
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoints: []string{"/a", "/b"}, Checks: []*core.Check{
+		{ID: "exposed", Name: "Exposed", Severity: "High", Matchers: core.Matchers{StatusCode: core.NewStatusCodes(200)}},
+	}}}}
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(404))
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 1)
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	history, err := monitor.NewHistory(dir, keep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emitter := &recordingEmitter{}
+	m := &monitor.Monitor{Scanner: scanner, Urls: []string{"http://site"}, History: history, Emitters: []monitor.IEmitter{emitter}}
+	return m, fetcher, emitter, dir
+}
+
+func TestScan(t *testing.T) {
+	m, fetcher, emitter, dir := newMonitor(t, 2)
+
+	var tests = []struct {
+		name    string
+		exposed []string
+		want    map[string]string
+	}{
+		{name: "first scan", exposed: []string{"http://site/a"}, want: map[string]string{"/a": core.StatusNew}},
+		{name: "no change", exposed: []string{"http://site/a"}, want: map[string]string{}},
+		{name: "new and resolved", exposed: []string{"http://site/b"}, want: map[string]string{"/a": core.StatusResolved, "/b": core.StatusNew}},
+		{name: "all resolved", exposed: nil, want: map[string]string{"/b": core.StatusResolved}},
+	}
+
+	emitted := 0
+	for _, tc := range tests {
+		expose(fetcher, tc.exposed...)
+		changes, err := m.Scan(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		have := make(map[string]string)
+		for _, change := range changes {
+			have[change.Endpoint] = change.Status
+		}
+		if len(have) != len(tc.want) {
+			t.Errorf("%s: expected: %v, got: %v", tc.name, tc.want, have)
+		}
+		for endpoint, status := range tc.want {
+			if have[endpoint] != status {
+				t.Errorf("%s: expected: %v, got: %v", tc.name, tc.want, have)
+			}
+		}
+		if len(changes) > 0 {
+			emitted++
+		}
+		if len(emitter.emitted) != emitted {
+			t.Errorf("%s: expected the changes to be emitted only when there are some, got %d emits", tc.name, len(emitter.emitted))
+		}
+		// the files of the history are named after the millisecond of the scan
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected the history to keep 2 scans, got %d files", len(files))
+	}
+}
+
+func TestScanSuppressions(t *testing.T) {
+	m, fetcher, emitter, _ := newMonitor(t, 0)
+	m.Suppressions = &core.Suppressions{Suppressions: []*core.Suppression{{URL: "http://site/a", Justification: "accepted"}}}
+	if err := m.Suppressions.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expose(fetcher, "http://site/a")
+	changes, err := m.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 || len(emitter.emitted) != 0 {
+		t.Errorf("expected the suppressed finding not to be emitted, got: %v", changes)
+	}
+}
+
+// soonSchedule runs every 10 milliseconds
+type soonSchedule struct{}
+
+func (soonSchedule) Next(t time.Time) time.Time {
+	return t.Add(10 * time.Millisecond)
+}
+
+func TestRun(t *testing.T) {
+	m, fetcher, emitter, _ := newMonitor(t, 0)
+	expose(fetcher, "http://site/a")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := m.Run(ctx, soonSchedule{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(emitter.emitted) != 1 {
+		t.Errorf("expected only the first scan to emit changes, got %d emits", len(emitter.emitted))
+	}
+}
+
+func TestWebhookEmitter(t *testing.T) {
+	var payload struct {
+		Changes []core.Output `json:"changes"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	emitter := monitor.WebhookEmitter{URL: server.URL, Client: server.Client()}
+	if err := emitter.Emit(time.Now(), []core.Output{{URL: "http://site/a", ID: "exposed", Status: core.StatusNew}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Changes) != 1 || payload.Changes[0].Status != core.StatusNew {
+		t.Errorf("expected the change to be posted, got: %v", payload)
+	}
+
+	failing := monitor.WebhookEmitter{URL: server.URL + "/missing", Client: &http.Client{Transport: roundTripper(http.StatusInternalServerError)}}
+	if err := failing.Emit(time.Now(), nil); err == nil {
+		t.Errorf("expected an error on a failed delivery")
+	}
+}
+
+// roundTripper answers every request with a status code
+type roundTripper int
+
+func (code roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: int(code), Body: ioutil.NopCloser(strings.NewReader("")), Request: r}, nil
+}
@@ -0,0 +1,360 @@
+package nuclei
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const baseURL = "{{BaseURL}}"
+
+// defaultRemediation is used when the template doesn't provide one, chopchop requiring it
+const defaultRemediation = "Restrict access to this resource, see references for details"
+
+// StringList accepts both a YAML list and a comma separated string, as nuclei templates use both
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*l = list
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	*l = nil
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// Template is the subset of a nuclei template that can be converted
+type Template struct {
+	ID       string    `yaml:"id"`
+	Info     Info      `yaml:"info"`
+	Requests []Request `yaml:"requests"`
+	HTTP     []Request `yaml:"http"`
+}
+
+type Info struct {
+	Name           string         `yaml:"name"`
+	Severity       string         `yaml:"severity"`
+	Description    string         `yaml:"description"`
+	Remediation    string         `yaml:"remediation"`
+	Reference      StringList     `yaml:"reference"`
+	Tags           StringList     `yaml:"tags"`
+	Classification Classification `yaml:"classification"`
+}
+
+type Classification struct {
+	CVE StringList `yaml:"cve-id"`
+	CWE StringList `yaml:"cwe-id"`
+}
+
+type Request struct {
+	Method            string            `yaml:"method"`
+	Path              []string          `yaml:"path"`
+	Raw               []string          `yaml:"raw"`
+	Body              string            `yaml:"body"`
+	Headers           map[string]string `yaml:"headers"`
+	Payloads          interface{}       `yaml:"payloads"`
+	Redirects         bool              `yaml:"redirects"`
+	MatchersCondition string            `yaml:"matchers-condition"`
+	Matchers          []Matcher         `yaml:"matchers"`
+	// Unsupported holds the fields that aren't translated, which make the template unconvertible
+	Unsupported map[string]interface{} `yaml:",inline"`
+}
+
+type Matcher struct {
+	Type      string   `yaml:"type"`
+	Part      string   `yaml:"part"`
+	Words     []string `yaml:"words"`
+	Status    []int    `yaml:"status"`
+	Condition string   `yaml:"condition"`
+	Negative  bool     `yaml:"negative"`
+	// Unsupported holds the fields that aren't translated, which make the template unconvertible
+	Unsupported map[string]interface{} `yaml:",inline"`
+}
+
+// ignoredMatcherFields don't change what a matcher matches
+var ignoredMatcherFields = []string{"name"}
+
+// Parse reads a nuclei template
+func Parse(data []byte) (*Template, error) {
+	template := new(Template)
+	if err := yaml.Unmarshal(data, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Convert turns a simple HTTP template into a chopchop plugin holding a single check.
+// It returns an error explaining why the template can't be converted otherwise.
+func Convert(template *Template) (*core.Plugin, error) {
+	requests := append(template.Requests, template.HTTP...)
+	if len(requests) != 1 {
+		return nil, fmt.Errorf("expected a single HTTP request, found %d", len(requests))
+	}
+	request := requests[0]
+	if method := strings.ToUpper(request.Method); method != "" && method != "GET" {
+		return nil, fmt.Errorf("unsupported method %s", request.Method)
+	}
+	if len(request.Raw) > 0 {
+		return nil, fmt.Errorf("raw requests are not supported")
+	}
+	if request.Body != "" || len(request.Headers) > 0 || request.Payloads != nil {
+		return nil, fmt.Errorf("request body, headers and payloads are not supported")
+	}
+	if len(request.Path) == 0 {
+		return nil, fmt.Errorf("missing request path")
+	}
+	if err := unsupportedFields(request.Unsupported, nil); err != nil {
+		return nil, err
+	}
+	if !validCondition(request.MatchersCondition) {
+		return nil, fmt.Errorf("unsupported matchers-condition %s", request.MatchersCondition)
+	}
+
+	severity, err := convertSeverity(template.Info.Severity)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &core.Check{
+		ID:          template.ID,
+		Name:        template.Info.Name,
+		Severity:    severity,
+		Description: strings.TrimSpace(template.Info.Description),
+		Remediation: strings.TrimSpace(template.Info.Remediation),
+		Tags:        template.Info.Tags,
+		References:  template.Info.Reference,
+		CVE:         template.Info.Classification.CVE,
+		CWE:         template.Info.Classification.CWE,
+	}
+	if check.Name == "" {
+		check.Name = template.ID
+	}
+	if check.Description == "" {
+		check.Description = check.Name
+	}
+	if check.Remediation == "" {
+		check.Remediation = defaultRemediation
+	}
+
+	if err := convertMatchers(request, check); err != nil {
+		return nil, err
+	}
+
+	plugin := &core.Plugin{
+		Checks:          []*core.Check{check},
+		FollowRedirects: request.Redirects,
+	}
+	for _, path := range request.Path {
+		if !strings.HasPrefix(path, baseURL) {
+			return nil, fmt.Errorf("path %s doesn't start with %s", path, baseURL)
+		}
+		endpoint := strings.TrimPrefix(path, baseURL)
+		if strings.Contains(endpoint, "{{") {
+			return nil, fmt.Errorf("path %s uses unsupported variables", path)
+		}
+		if endpoint == "" {
+			endpoint = "/"
+		}
+		plugin.Endpoints = append(plugin.Endpoints, endpoint)
+	}
+	if len(plugin.Endpoints) == 1 {
+		plugin.Endpoint = plugin.Endpoints[0]
+		plugin.Endpoints = nil
+	}
+	return plugin, nil
+}
+
+func convertSeverity(severity string) (string, error) {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "High", nil
+	case "medium":
+		return "Medium", nil
+	case "low":
+		return "Low", nil
+	case "info", "unknown", "":
+		return "Informational", nil
+	}
+	return "", fmt.Errorf("unknown severity %s", severity)
+}
+
+func convertMatchers(request Request, check *core.Check) error {
+	if len(request.Matchers) == 0 {
+		return fmt.Errorf("no matchers")
+	}
+	// with matchers-condition: or, each matcher becomes one of the alternatives of the check condition
+	or := len(request.Matchers) > 1 && request.MatchersCondition != "and"
+	if or {
+		check.Condition = &core.Condition{}
+	}
+	for i, matcher := range request.Matchers {
+		condition := &core.Condition{}
+		var err error
+		switch matcher.Type {
+		case "word":
+			err = convertWordMatcher(matcher, condition)
+		case "status":
+			err = convertStatusMatcher(matcher, &condition.Matchers)
+		default:
+			err = fmt.Errorf("unsupported matcher type %s", matcher.Type)
+		}
+		if err == nil {
+			err = unsupportedFields(matcher.Unsupported, ignoredMatcherFields)
+		}
+		if err == nil && !validCondition(matcher.Condition) {
+			err = fmt.Errorf("unsupported condition %s", matcher.Condition)
+		}
+		if err != nil {
+			return fmt.Errorf("matcher %d: %v", i, err)
+		}
+		if or {
+			check.Condition.Any = append(check.Condition.Any, condition)
+			continue
+		}
+		if err := mergeCondition(check, condition); err != nil {
+			return fmt.Errorf("matcher %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// unsupportedFields returns an error listing the fields that aren't translated, apart from the ignored ones
+func unsupportedFields(fields map[string]interface{}, ignored []string) error {
+	var names []string
+	for name := range fields {
+		if !containsString(ignored, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unsupported fields %s", strings.Join(names, ", "))
+}
+
+func validCondition(condition string) bool {
+	return condition == "" || condition == "and" || condition == "or"
+}
+
+func containsString(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCondition adds the condition of a matcher to the check.
+// Simple matchers are kept as check fields, the others going to the check condition.
+func mergeCondition(check *core.Check, condition *core.Condition) error {
+	m := condition.Matchers
+	if condition.Not != nil || len(condition.Any) > 0 || (len(m.MustMatchOne) > 0 && len(check.MustMatchOne) > 0) {
+		if check.Condition == nil {
+			check.Condition = &core.Condition{}
+		}
+		check.Condition.All = append(check.Condition.All, condition)
+		return nil
+	}
+	if m.StatusCode != nil || m.NotStatusCode != nil {
+		if check.StatusCode != nil || check.NotStatusCode != nil {
+			return fmt.Errorf("only one status matcher is supported")
+		}
+		check.StatusCode = m.StatusCode
+		check.NotStatusCode = m.NotStatusCode
+	}
+	if len(m.MustMatchOne) > 0 {
+		check.MustMatchOne = m.MustMatchOne
+	}
+	check.MustMatchAll = append(check.MustMatchAll, m.MustMatchAll...)
+	check.MustNotMatch = append(check.MustNotMatch, m.MustNotMatch...)
+	check.Headers = append(check.Headers, m.Headers...)
+	check.NoHeaders = append(check.NoHeaders, m.NoHeaders...)
+	return nil
+}
+
+func convertWordMatcher(matcher Matcher, condition *core.Condition) error {
+	if len(matcher.Words) == 0 {
+		return fmt.Errorf("no words")
+	}
+	all := matcher.Condition == "and" || len(matcher.Words) == 1
+	m := &condition.Matchers
+	switch matcher.Part {
+	case "", "body":
+		switch {
+		case matcher.Negative && all:
+			if len(matcher.Words) == 1 {
+				m.MustNotMatch = matcher.Words
+			} else {
+				condition.Not = &core.Condition{Matchers: core.Matchers{MustMatchAll: matcher.Words}}
+			}
+		case matcher.Negative:
+			m.MustNotMatch = matcher.Words
+		case all:
+			m.MustMatchAll = matcher.Words
+		default:
+			m.MustMatchOne = matcher.Words
+		}
+	case "header":
+		var headers []core.HeaderMatcher
+		for _, word := range matcher.Words {
+			header, err := convertHeader(word)
+			if err != nil {
+				return err
+			}
+			headers = append(headers, header)
+		}
+		switch {
+		case matcher.Negative && all && len(headers) > 1:
+			condition.Not = &core.Condition{Matchers: core.Matchers{Headers: headers}}
+		case matcher.Negative:
+			m.NoHeaders = headers
+		case all:
+			m.Headers = headers
+		default:
+			for _, header := range headers {
+				condition.Any = append(condition.Any, &core.Condition{Matchers: core.Matchers{Headers: []core.HeaderMatcher{header}}})
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported part %s", matcher.Part)
+	}
+	return nil
+}
+
+// convertHeader turns a "Name: value" word into a chopchop header matcher
+func convertHeader(word string) (core.HeaderMatcher, error) {
+	header := core.ParseHeaderMatcher(word)
+	if header.Name == "" || header.Value == "" {
+		return header, fmt.Errorf("header word %q is not in the Name: value format", word)
+	}
+	header.Name = http.CanonicalHeaderKey(header.Name)
+	return header, nil
+}
+
+func convertStatusMatcher(matcher Matcher, m *core.Matchers) error {
+	if len(matcher.Status) == 0 {
+		return fmt.Errorf("no status")
+	}
+	if matcher.Negative {
+		m.NotStatusCode = core.NewStatusCodes(matcher.Status...)
+	} else {
+		m.StatusCode = core.NewStatusCodes(matcher.Status...)
+	}
+	return nil
+}
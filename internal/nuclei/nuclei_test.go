@@ -0,0 +1,181 @@
+package nuclei_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal/nuclei"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	template := `
+id: git-config
+info:
+  name: Git Config File
+  severity: critical
+  tags: config,git
+  reference: https://example.com
+  classification:
+    cwe-id: CWE-200
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/.git/config"
+    matchers-condition: and
+    matchers:
+      - type: word
+        words:
+          - "[core]"
+          - "[remote"
+        condition: or
+      - type: word
+        part: header
+        words:
+          - "content-type: text/plain"
+      - type: word
+        words:
+          - "<html"
+        negative: true
+      - type: status
+        status:
+          - 200
+`
+	parsed, err := nuclei.Parse([]byte(template))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plugin, err := nuclei.Convert(parsed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &core.Plugin{
+		Endpoint: "/.git/config",
+		Checks: []*core.Check{{
+			Name:        "Git Config File",
+			ID:          "git-config",
+			Severity:    "High",
+			Description: "Git Config File",
+			Remediation: plugin.Checks[0].Remediation,
+			Tags:        []string{"config", "git"},
+			References:  []string{"https://example.com"},
+			CWE:         []string{"CWE-200"},
+			Matchers: core.Matchers{
+				StatusCode:   core.NewStatusCodes(200),
+				MustMatchOne: []string{"[core]", "[remote"},
+				MustNotMatch: []string{"<html"},
+				Headers:      []core.HeaderMatcher{{Name: "Content-Type", Value: "text/plain"}},
+			},
+		}},
+	}
+	if !plugin.Equals(want) || !plugin.Checks[0].Equals(want.Checks[0]) {
+		t.Errorf("expected: %+v, got: %+v", want.Checks[0], plugin.Checks[0])
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	var tests = map[string]struct {
+		template string
+		reason   string
+	}{
+		"POST request": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: POST\n    path: ['{{BaseURL}}/']\n    matchers: [{type: status, status: [200]}]\n",
+			reason:   "unsupported method",
+		},
+		"Several requests": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - path: ['{{BaseURL}}/']\n  - path: ['{{BaseURL}}/a']\n",
+			reason:   "single HTTP request",
+		},
+		"Regex matcher": {
+			template: "id: a\ninfo: {name: a, severity: low}\nhttp:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: regex, regex: ['a.*']}]\n",
+			reason:   "unsupported matcher type regex",
+		},
+		"Unsupported part": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: word, part: all, words: [a]}]\n",
+			reason:   "unsupported part all",
+		},
+		"Case-insensitive words": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: word, words: [a], case-insensitive: true}]\n",
+			reason:   "unsupported fields case-insensitive",
+		},
+		"Encoded words": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: word, words: ['61'], encoding: hex}]\n",
+			reason:   "unsupported fields encoding",
+		},
+		"Request options": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    max-size: 100\n    matchers: [{type: status, status: [200]}]\n",
+			reason:   "unsupported fields max-size",
+		},
+		"Unknown condition": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: word, words: [a, b], condition: xor}]\n",
+			reason:   "unsupported condition xor",
+		},
+		"Path variables": {
+			template: "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/{{randstr}}']\n    matchers: [{type: status, status: [200]}]\n",
+			reason:   "unsupported variables",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := nuclei.Parse([]byte(tc.template))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = nuclei.Convert(parsed)
+			if err == nil || !strings.Contains(err.Error(), tc.reason) {
+				t.Errorf("expected an error containing %q, got: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestConvertOrMatchers(t *testing.T) {
+	template := `
+id: or
+info:
+  name: Or
+  severity: low
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/"
+    matchers:
+      - type: word
+        part: header
+        words:
+          - "X-Powered-By: PHP"
+      - type: word
+        words:
+          - "phpinfo()"
+          - "PHP Version"
+        condition: and
+        negative: true
+`
+	parsed, err := nuclei.Parse([]byte(template))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plugin, err := nuclei.Convert(parsed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &core.Condition{Any: []*core.Condition{
+		{Matchers: core.Matchers{Headers: []core.HeaderMatcher{{Name: "X-Powered-By", Value: "PHP"}}}},
+		{Not: &core.Condition{Matchers: core.Matchers{MustMatchAll: []string{"phpinfo()", "PHP Version"}}}},
+	}}
+	have := plugin.Checks[0].Condition
+	if have == nil || !have.Equals(want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+}
+
+func TestConvertNamedMatcher(t *testing.T) {
+	template := "id: a\ninfo: {name: a, severity: low}\nrequests:\n  - method: GET\n    path: ['{{BaseURL}}/']\n    matchers: [{type: status, name: ok, status: [200]}]\n"
+	parsed, err := nuclei.Parse([]byte(template))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nuclei.Convert(parsed); err != nil {
+		t.Errorf("expected the name of the matcher to be ignored, got: %v", err)
+	}
+}
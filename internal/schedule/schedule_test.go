@@ -0,0 +1,49 @@
+package schedule_test
+
+import (
+	"gochopchop/internal/schedule"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2021, time.March, 10, 10, 7, 30, 0, time.UTC)
+	var tests = map[string]struct {
+		spec string
+		want time.Time
+	}{
+		"every minute":         {spec: "* * * * *", want: time.Date(2021, time.March, 10, 10, 8, 0, 0, time.UTC)},
+		"every 15 minutes":     {spec: "*/15 * * * *", want: time.Date(2021, time.March, 10, 10, 15, 0, 0, time.UTC)},
+		"hourly":               {spec: "@hourly", want: time.Date(2021, time.March, 10, 11, 0, 0, 0, time.UTC)},
+		"daily":                {spec: "@daily", want: time.Date(2021, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		"list and range":       {spec: "30 2,4-6 * * *", want: time.Date(2021, time.March, 11, 2, 30, 0, 0, time.UTC)},
+		"weekdays":             {spec: "0 9 * * 1-5", want: time.Date(2021, time.March, 11, 9, 0, 0, 0, time.UTC)},
+		"sunday as 7":          {spec: "0 0 * * 7", want: time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		"next month":           {spec: "0 0 1 * *", want: time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		"day of month or week": {spec: "0 0 1 * 5", want: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC)},
+		"leap day":             {spec: "0 0 29 2 *", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		"every duration":       {spec: "@every 6h", want: from.Add(6 * time.Hour)},
+		"never":                {spec: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := schedule.Parse(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := s.Next(from); !have.Equal(tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every", "@every 1ms", "@yearly"} {
+		if _, err := schedule.Parse(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gochopchop/core"
+	"sync"
+	"time"
+)
+
+// Statuses of a scan job
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// ScanRequest is the body of a scan submission: the targets and the filters selecting the checks to run
+type ScanRequest struct {
+	URLs         []string `json:"urls"`
+	MinSeverity  string   `json:"minSeverity,omitempty"`
+	Severities   []string `json:"severities,omitempty"`
+	IDs          []string `json:"ids,omitempty"`
+	ExcludeIDs   []string `json:"excludeIds,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	ExcludeTags  []string `json:"excludeTags,omitempty"`
+	Names        []string `json:"names,omitempty"`
+	ExcludeNames []string `json:"excludeNames,omitempty"`
+}
+
+// filter returns the filter of the request, an error if a target or a severity is invalid
+func (r *ScanRequest) filter() (*core.Filter, error) {
+	if len(r.URLs) == 0 {
+		return nil, fmt.Errorf("No url provided")
+	}
+	for _, url := range r.URLs {
+		if !isURL(url) {
+			return nil, fmt.Errorf("Invalid url : %s", url)
+		}
+	}
+	if r.MinSeverity != "" && !core.ValidSeverity(r.MinSeverity) {
+		return nil, fmt.Errorf("Invalid min severity level : %s. Please use : %s", r.MinSeverity, core.SeveritiesAsString())
+	}
+	for _, severity := range r.Severities {
+		if !core.ValidSeverity(severity) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, core.SeveritiesAsString())
+		}
+	}
+	return &core.Filter{
+		MinSeverity:  r.MinSeverity,
+		Severities:   r.Severities,
+		IDs:          r.IDs,
+		ExcludeIDs:   r.ExcludeIDs,
+		Tags:         r.Tags,
+		ExcludeTags:  r.ExcludeTags,
+		Names:        r.Names,
+		ExcludeNames: r.ExcludeNames,
+	}, nil
+}
+
+// Progress counts the jobs of a scan, the run of the checks of a plugin on one URL, completed out of the total
+type Progress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// JobStatus is the status of a scan job returned by the API
+type JobStatus struct {
+	ID       string        `json:"id"`
+	Status   string        `json:"status"`
+	URLs     []string      `json:"urls"`
+	Progress Progress      `json:"progress"`
+	Findings int           `json:"findings"`
+	Error    string        `json:"error,omitempty"`
+	NotRun   []core.NotRun `json:"notRun,omitempty"`
+	Created  time.Time     `json:"created"`
+	Started  *time.Time    `json:"started,omitempty"`
+	Finished *time.Time    `json:"finished,omitempty"`
+}
+
+// job is a scan submitted to the server
+type job struct {
+	mux        sync.Mutex
+	id         string
+	urls       []string
+	filter     *core.Filter
+	signatures *core.Signatures
+	// state holds the progress and the findings of the scan
+	state     *core.ScanState
+	total     int
+	status    string
+	err       string
+	notRun    []core.NotRun
+	created   time.Time
+	started   time.Time
+	finished  time.Time
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func newJob(urls []string, filter *core.Filter, signatures *core.Signatures) (*job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	requests := 0
+	for _, plugin := range signatures.Plugins {
+		requests += len(plugin.GetEndpoints())
+	}
+	return &job{
+		id:         id,
+		urls:       urls,
+		filter:     filter,
+		signatures: signatures,
+		state:      core.NewScanState(urls),
+		total:      requests * len(urls),
+		status:     StatusQueued,
+		created:    time.Now(),
+	}, nil
+}
+
+// newID returns a random job id
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// start marks the job as running, returning false if it was cancelled while queued
+func (j *job) start(cancel context.CancelFunc) bool {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.cancelled {
+		return false
+	}
+	j.status = StatusRunning
+	j.started = time.Now()
+	j.cancel = cancel
+	return true
+}
+
+// finish records the outcome of the scan
+func (j *job) finish(err error, maxDuration time.Duration) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.finished = time.Now()
+	j.cancel = nil
+	var notRun *core.NotRunError
+	switch {
+	case err == nil:
+		j.status = StatusDone
+	case errors.As(err, &notRun):
+		j.status = StatusDone
+		j.notRun = notRun.NotRun
+	case j.cancelled:
+		j.status = StatusCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		j.status = StatusFailed
+		j.err = fmt.Sprintf("Scan stopped by the max duration of %s, the results are incomplete", maxDuration)
+	case errors.Is(err, context.Canceled):
+		j.status = StatusCancelled
+		j.err = "Scan stopped by the shutdown of the server, the results are incomplete"
+	default:
+		j.status = StatusFailed
+		j.err = err.Error()
+	}
+}
+
+// stop cancels the job, returning false if it was already over
+func (j *job) stop() bool {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	switch j.status {
+	case StatusQueued:
+		j.cancelled = true
+		j.status = StatusCancelled
+		j.finished = time.Now()
+	case StatusRunning:
+		j.cancelled = true
+		j.cancel()
+	default:
+		return false
+	}
+	return true
+}
+
+// incomplete returns true until the job has run every check, while it runs or once it was stopped
+func (j *job) incomplete() bool {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	return j.status != StatusDone
+}
+
+// over returns true once the job won't change anymore
+func (j *job) over() bool {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	return j.status != StatusQueued && j.status != StatusRunning
+}
+
+// snapshot returns the current status of the job
+func (j *job) snapshot() JobStatus {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	status := JobStatus{
+		ID:       j.id,
+		Status:   j.status,
+		URLs:     j.urls,
+		Progress: Progress{Completed: j.state.Completed(), Total: j.total},
+		Findings: len(j.state.Outputs()),
+		Error:    j.err,
+		NotRun:   j.notRun,
+		Created:  j.created,
+	}
+	if started := j.started; !started.IsZero() {
+		status.Started = &started
+	}
+	if finished := j.finished; !finished.IsZero() {
+		status.Finished = &finished
+	}
+	return status
+}
@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/export"
+	"gochopchop/internal/formatting"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRequestSize is the maximum size of the body of a scan submission
+const maxRequestSize = 1 << 20
+
+// Server is the HTTP API running scans in the background: scans are submitted to a queue
+// that a number of workers run one at a time, their status and results being polled afterwards.
+type Server struct {
+	// scanner is the base of the scanner of every job, copied with the signatures selected by its filters
+	scanner *core.Scanner
+	// MaxDuration bounds each scan, 0 for no limit
+	MaxDuration time.Duration
+	// Token is the bearer token required by every request when set
+	Token string
+	// History is the number of finished scans kept, the oldest being forgotten first
+	History int
+	queue   chan *job
+	mux     sync.Mutex
+	jobs    map[string]*job
+	order   []*job
+}
+
+// New returns a server running the scans with scanner, queueSize scans at most waiting to be run
+func New(scanner *core.Scanner, queueSize int) *Server {
+	return &Server{
+		scanner: scanner,
+		History: 100,
+		queue:   make(chan *job, queueSize),
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Start runs the queued scans with workers until ctx is done, the running scans being then cancelled
+func (s *Server) Start(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-s.queue:
+					s.run(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+func (s *Server) run(ctx context.Context, j *job) {
+	var cancel context.CancelFunc
+	if s.MaxDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.MaxDuration)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	if !j.start(cancel) {
+		return
+	}
+	log.Infof("Running scan %s of %d urls", j.id, len(j.urls))
+
+	// the scanner is shared, the copy of each job only differing by its checks and its state
+	scanner := *s.scanner
+	scanner.Signatures = j.signatures
+	if scanner.HeaderAudit != nil {
+		scanner.HeaderAudit = scanner.HeaderAudit.Filtered(j.filter)
+	}
+	scanner.State = j.state
+	_, err := scanner.Scan(ctx, j.urls)
+	j.finish(err, s.MaxDuration)
+	log.Infof("Scan %s finished with %d findings", j.id, len(j.state.Outputs()))
+}
+
+// submit queues a scan, returning false if the queue is full
+func (s *Server) submit(j *job) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	select {
+	case s.queue <- j:
+	default:
+		return false
+	}
+	s.jobs[j.id] = j
+	s.order = append(s.order, j)
+	s.forget()
+	return true
+}
+
+// forget drops the oldest finished jobs over the history
+func (s *Server) forget() {
+	finished := 0
+	for _, j := range s.order {
+		if j.over() {
+			finished++
+		}
+	}
+	kept := s.order[:0]
+	for _, j := range s.order {
+		if finished > s.History && j.over() {
+			delete(s.jobs, j.id)
+			finished--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	s.order = kept
+}
+
+func (s *Server) job(id string) *job {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.jobs[id]
+}
+
+func (s *Server) list() []JobStatus {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	statuses := make([]JobStatus, len(s.order))
+	for i, j := range s.order {
+		statuses[i] = j.snapshot()
+	}
+	return statuses
+}
+
+// ServeHTTP routes the requests of the API:
+//
+//	GET    /signatures                  the checks loaded, ?format=json (default), yaml, csv, markdown or table
+//	GET    /scans                       the status of every scan
+//	POST   /scans                       submits a scan, a ScanRequest
+//	GET    /scans/{id}                  the status of a scan
+//	DELETE /scans/{id}                  cancels a scan
+//	GET    /scans/{id}/results          the findings of a scan, ?format=json (default) or csv
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("Invalid or missing bearer token"))
+			return
+		}
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "signatures":
+		s.handleSignatures(w, r)
+	case len(path) == 1 && path[0] == "scans":
+		s.handleScans(w, r)
+	case len(path) == 2 && path[0] == "scans":
+		s.handleScan(w, r, path[1])
+	case len(path) == 3 && path[0] == "scans" && path[2] == "results":
+		s.handleResults(w, r, path[1])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("Not found : %s", r.URL.Path))
+	}
+}
+
+func (s *Server) handleSignatures(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if !formatting.ValidSignatureFormat(format) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid format : %s. Please use : %s", format, strings.Join(formatting.SignatureFormats, ", ")))
+		return
+	}
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if err := formatting.PrintCheckEntries(formatting.CheckEntries(s.scanner.Signatures), format, w); err != nil {
+		log.Error(err)
+	}
+}
+
+func (s *Server) handleScans(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.list())
+		return
+	}
+
+	var request ScanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid scan request : %v", err))
+		return
+	}
+	filter, err := request.filter()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	signatures := s.scanner.Signatures.Filtered(filter)
+	if len(signatures.Plugins) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("No check left to run after applying the filters"))
+		return
+	}
+	j, err := newJob(request.URLs, filter, signatures)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !s.submit(j) {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("Too many scans queued, please retry later"))
+		return
+	}
+	w.Header().Set("Location", "/scans/"+j.id)
+	writeJSON(w, http.StatusAccepted, j.snapshot())
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request, id string) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodDelete) {
+		return
+	}
+	j := s.job(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown scan : %s", id))
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if !j.stop() {
+			writeError(w, http.StatusConflict, fmt.Errorf("Scan %s is already over", id))
+			return
+		}
+		writeJSON(w, http.StatusAccepted, j.snapshot())
+		return
+	}
+	writeJSON(w, http.StatusOK, j.snapshot())
+}
+
+// handleResults returns the findings of the scan, the ones gathered so far while it runs
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request, id string) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	j := s.job(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown scan : %s", id))
+		return
+	}
+	outputs := j.state.Outputs()
+	if j.incomplete() {
+		outputs = core.MarkIncomplete(outputs)
+	}
+	var err error
+	switch format := r.URL.Query().Get("format"); format {
+	case "json", "":
+		w.Header().Set("Content-Type", "application/json")
+		err = export.WriteJSON(w, outputs)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = export.WriteCSV(w, outputs)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid export format : %s. Please use : json, csv", format))
+		return
+	}
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// allowMethods returns true if the method of the request is one of methods, answering 405 otherwise
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method not allowed : %s", r.Method))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func isURL(str string) bool {
+	u, err := url.Parse(str)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,229 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gochopchop/core"
+	"gochopchop/internal/server"
+	"gochopchop/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer(t *testing.T, queueSize int, workers int) (*server.Server, *httptest.Server) {
+	signatures, err := core.ParseSignatures([]byte("plugins:\n" +
+		"- endpoints: [/a, /b]\n  checks:\n  - {name: Found, id: found, description: d, remediation: r, severity: Low, status_code: 200}\n" +
+		"- endpoint: /c\n  checks:\n  - {name: Critical, id: critical, description: d, remediation: r, severity: High, status_code: 200}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the urls of the slow target are blocked until the scan is cancelled
+	fetcher := mock.NewFetcher(nil, mock.WithStatusCode(200), mock.WithDelay("http://slow", time.Hour))
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 1)
+	api := server.New(scanner, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	api.Start(ctx, workers)
+	ts := httptest.NewServer(api)
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+	})
+	return api, ts
+}
+
+func request(t *testing.T, method string, url string, body string) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	return resp, buf.Bytes()
+}
+
+func submit(t *testing.T, ts *httptest.Server, body string) server.JobStatus {
+	resp, data := request(t, http.MethodPost, ts.URL+"/scans", body)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, resp.StatusCode, data)
+	}
+	var status server.JobStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+// wait polls the status of the scan until it is over
+func wait(t *testing.T, ts *httptest.Server, id string) server.JobStatus {
+	for i := 0; i < 100; i++ {
+		_, data := request(t, http.MethodGet, ts.URL+"/scans/"+id, "")
+		var status server.JobStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Status != server.StatusQueued && status.Status != server.StatusRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("scan %s still running", id)
+	return server.JobStatus{}
+}
+
+func TestScan(t *testing.T) {
+	_, ts := newServer(t, 10, 1)
+
+	submitted := submit(t, ts, `{"urls": ["http://site"], "minSeverity": "Low", "excludeIds": ["critical"]}`)
+	if submitted.Progress.Total != 2 {
+		t.Errorf("expected 2 jobs, got: %v", submitted.Progress)
+	}
+	status := wait(t, ts, submitted.ID)
+	if status.Status != server.StatusDone || status.Findings != 2 || status.Progress.Completed != 2 {
+		t.Errorf("expected the scan to be done with 2 findings, got: %+v", status)
+	}
+
+	resp, data := request(t, http.MethodGet, ts.URL+"/scans/"+submitted.ID+"/results", "")
+	var outputs []core.Output
+	if err := json.Unmarshal(data, &outputs); err != nil || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("expected JSON results, got: %s", data)
+	}
+	for _, output := range outputs {
+		if output.ID != "found" {
+			t.Errorf("expected the excluded check not to run, got: %v", output)
+		}
+		if output.Incomplete {
+			t.Errorf("expected the results of a complete scan, got: %v", output)
+		}
+	}
+	_, data = request(t, http.MethodGet, ts.URL+"/scans/"+submitted.ID+"/results?format=csv", "")
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 {
+		t.Errorf("expected a CSV export of 2 findings, got: %s", data)
+	}
+
+	_, data = request(t, http.MethodGet, ts.URL+"/scans", "")
+	var statuses []server.JobStatus
+	if err := json.Unmarshal(data, &statuses); err != nil || len(statuses) != 1 || statuses[0].ID != submitted.ID {
+		t.Errorf("expected the scan to be listed, got: %s", data)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	_, ts := newServer(t, 10, 1)
+
+	running := submit(t, ts, `{"urls": ["http://slow"]}`)
+	queued := submit(t, ts, `{"urls": ["http://site"]}`)
+
+	for _, id := range []string{queued.ID, running.ID} {
+		if resp, data := request(t, http.MethodDelete, ts.URL+"/scans/"+id, ""); resp.StatusCode != http.StatusAccepted {
+			t.Errorf("expected status %d, got %d: %s", http.StatusAccepted, resp.StatusCode, data)
+		}
+		if status := wait(t, ts, id); status.Status != server.StatusCancelled {
+			t.Errorf("expected the scan to be cancelled, got: %+v", status)
+		}
+	}
+	if resp, _ := request(t, http.MethodDelete, ts.URL+"/scans/"+running.ID, ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestCancelledResultsIncomplete(t *testing.T) {
+	_, ts := newServer(t, 10, 1)
+
+	running := submit(t, ts, `{"urls": ["http://site", "http://slow"], "ids": ["found"]}`)
+	for i := 0; i < 100; i++ {
+		_, data := request(t, http.MethodGet, ts.URL+"/scans/"+running.ID, "")
+		var status server.JobStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Findings == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	request(t, http.MethodDelete, ts.URL+"/scans/"+running.ID, "")
+	wait(t, ts, running.ID)
+
+	_, data := request(t, http.MethodGet, ts.URL+"/scans/"+running.ID+"/results", "")
+	var outputs []core.Output
+	if err := json.Unmarshal(data, &outputs); err != nil || len(outputs) != 2 {
+		t.Fatalf("expected the 2 findings of the site, got: %s", data)
+	}
+	for _, output := range outputs {
+		if !output.Incomplete {
+			t.Errorf("expected the results of a cancelled scan to be incomplete, got: %v", output)
+		}
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	_, ts := newServer(t, 1, 0)
+	var tests = map[string]struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		"no url":          {method: http.MethodPost, path: "/scans", body: `{"urls": []}`, want: http.StatusBadRequest},
+		"invalid url":     {method: http.MethodPost, path: "/scans", body: `{"urls": ["file:///etc/passwd"]}`, want: http.StatusBadRequest},
+		"unknown field":   {method: http.MethodPost, path: "/scans", body: `{"urls": ["http://site"], "url": "x"}`, want: http.StatusBadRequest},
+		"severity":        {method: http.MethodPost, path: "/scans", body: `{"urls": ["http://site"], "severities": ["Huge"]}`, want: http.StatusBadRequest},
+		"no check left":   {method: http.MethodPost, path: "/scans", body: `{"urls": ["http://site"], "tags": ["none"]}`, want: http.StatusBadRequest},
+		"unknown scan":    {method: http.MethodGet, path: "/scans/unknown", want: http.StatusNotFound},
+		"unknown results": {method: http.MethodGet, path: "/scans/unknown/results", want: http.StatusNotFound},
+		"unknown path":    {method: http.MethodGet, path: "/", want: http.StatusNotFound},
+		"method":          {method: http.MethodPut, path: "/scans", want: http.StatusMethodNotAllowed},
+		"format":          {method: http.MethodGet, path: "/signatures?format=xml", want: http.StatusBadRequest},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if resp, data := request(t, tc.method, ts.URL+tc.path, tc.body); resp.StatusCode != tc.want {
+				t.Errorf("expected status %d, got %d: %s", tc.want, resp.StatusCode, data)
+			}
+		})
+	}
+
+	// without workers, the second scan doesn't fit in the queue
+	submit(t, ts, `{"urls": ["http://site"]}`)
+	if resp, _ := request(t, http.MethodPost, ts.URL+"/scans", `{"urls": ["http://site"]}`); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+func TestSignatures(t *testing.T) {
+	_, ts := newServer(t, 1, 0)
+	_, data := request(t, http.MethodGet, ts.URL+"/signatures", "")
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 2 {
+		t.Errorf("expected the 2 checks, got: %s", data)
+	}
+}
+
+func TestToken(t *testing.T) {
+	api, ts := newServer(t, 1, 0)
+	api.Token = "secret"
+
+	if resp, _ := request(t, http.MethodGet, ts.URL+"/scans", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/scans", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
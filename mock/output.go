@@ -10,6 +10,8 @@ var FakeOutputStatusCode = core.Output{
 	Name:        FakeCheckStatusCode200.Name,
 	Severity:    FakeCheckStatusCode200.Severity,
 	Remediation: FakeCheckStatusCode200.Remediation,
+	ID:          FakeCheckStatusCode200.ID,
+	Tags:        FakeCheckStatusCode200.Tags,
 }
 
 var FakeOutputMatchOne = core.Output{
@@ -18,6 +20,8 @@ var FakeOutputMatchOne = core.Output{
 	Name:        FakeCheckMatchOne.Name,
 	Severity:    FakeCheckMatchOne.Severity,
 	Remediation: FakeCheckMatchOne.Remediation,
+	ID:          FakeCheckMatchOne.ID,
+	Tags:        FakeCheckMatchOne.Tags,
 }
 var FakeOutputMatchAll = core.Output{
 	URL:         "http://problems",
@@ -25,6 +29,8 @@ var FakeOutputMatchAll = core.Output{
 	Name:        FakeCheckMatchAll.Name,
 	Severity:    FakeCheckMatchAll.Severity,
 	Remediation: FakeCheckMatchAll.Remediation,
+	ID:          FakeCheckMatchAll.ID,
+	Tags:        FakeCheckMatchAll.Tags,
 }
 
 var FakeOutputNotMatch = core.Output{
@@ -33,6 +39,8 @@ var FakeOutputNotMatch = core.Output{
 	Name:        FakeCheckNotMatch.Name,
 	Severity:    FakeCheckNotMatch.Severity,
 	Remediation: FakeCheckNotMatch.Remediation,
+	ID:          FakeCheckNotMatch.ID,
+	Tags:        FakeCheckNotMatch.Tags,
 }
 
 var FakeOutputNoHeaders = core.Output{
@@ -41,6 +49,8 @@ var FakeOutputNoHeaders = core.Output{
 	Name:        FakeCheckNoHeaders.Name,
 	Severity:    FakeCheckNoHeaders.Severity,
 	Remediation: FakeCheckNoHeaders.Remediation,
+	ID:          FakeCheckNoHeaders.ID,
+	Tags:        FakeCheckNoHeaders.Tags,
 }
 
 var FakeOutputHeaders = core.Output{
@@ -49,6 +59,8 @@ var FakeOutputHeaders = core.Output{
 	Name:        FakeCheckHeaders.Name,
 	Severity:    FakeCheckHeaders.Severity,
 	Remediation: FakeCheckHeaders.Remediation,
+	ID:          FakeCheckHeaders.ID,
+	Tags:        FakeCheckHeaders.Tags,
 }
 
 var FakeOutput = []core.Output{
@@ -60,6 +72,6 @@ var FakeOutput = []core.Output{
 	FakeOutputNotMatch,
 }
 
-var FakeOutputAsCSV = "url,endpoint,severity,checkName,remediation\nhttp://problems,/,Medium,StatusCode200,uninstall\nhttp://problems,/,High,Headers,uninstall\nhttp://problems,/,Low,NoHeaders,uninstall\nhttp://problems,/,Informational,MustMatchAll,uninstall\nhttp://problems,/,Low,MustMatchOne,uninstall\nhttp://problems,/,High,MustNotMatch,uninstall\n"
+var FakeOutputAsCSV = "url,endpoint,severity,checkName,remediation,id,tags,references,cwe,cve,status,suppression,justification,incomplete\nhttp://problems,/,Medium,StatusCode200,uninstall,status-code-200,exposure,,,,,,,\nhttp://problems,/,High,Headers,uninstall,headers,cms;noisy,,,,,,,\nhttp://problems,/,Low,NoHeaders,uninstall,no-headers,,,,,,,,\nhttp://problems,/,Informational,MustMatchAll,uninstall,must-match-all,,,,,,,,\nhttp://problems,/,Low,MustMatchOne,uninstall,must-match-one,,,,,,,,\nhttp://problems,/,High,MustNotMatch,uninstall,must-not-match,,,,,,,,\n"
 var FakeOutputAsTable = "+-----------------+----------+---------------+---------------+-------------+\n| URL             | ENDPOINT | SEVERITY      | PLUGIN        | REMEDIATION |\n+-----------------+----------+---------------+---------------+-------------+\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | Headers       | uninstall   |\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | MustNotMatch  | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | NoHeaders     | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | MustMatchOne  | uninstall   |\n| http://problems | /        | \x1b[33mMedium\x1b[0m        | StatusCode200 | uninstall   |\n| http://problems | /        | \x1b[36mInformational\x1b[0m | MustMatchAll  | uninstall   |\n+-----------------+----------+---------------+---------------+-------------+\n"
-var FakeOutputAsJSON = "[{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"StatusCode200\",\"severity\":\"Medium\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"Headers\",\"severity\":\"High\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"NoHeaders\",\"severity\":\"Low\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchAll\",\"severity\":\"Informational\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchOne\",\"severity\":\"Low\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustNotMatch\",\"severity\":\"High\",\"remediation\":\"uninstall\"}]"
+var FakeOutputAsJSON = "[{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"StatusCode200\",\"severity\":\"Medium\",\"remediation\":\"uninstall\",\"id\":\"status-code-200\",\"tags\":[\"exposure\"]},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"Headers\",\"severity\":\"High\",\"remediation\":\"uninstall\",\"id\":\"headers\",\"tags\":[\"cms\",\"noisy\"]},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"NoHeaders\",\"severity\":\"Low\",\"remediation\":\"uninstall\",\"id\":\"no-headers\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchAll\",\"severity\":\"Informational\",\"remediation\":\"uninstall\",\"id\":\"must-match-all\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchOne\",\"severity\":\"Low\",\"remediation\":\"uninstall\",\"id\":\"must-match-one\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustNotMatch\",\"severity\":\"High\",\"remediation\":\"uninstall\",\"id\":\"must-not-match\"}]"
@@ -1,10 +1,14 @@
 package mock
 
 import (
+	"context"
 	"fmt"
 	"gochopchop/core"
 	"gochopchop/internal"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 var FakeScanner = core.NewScanner(MyFakeFetcher, MyFakeFetcher, FakeSignatures, 1)
@@ -40,3 +44,155 @@ var MyFakeFetcher = FakeFetcherWithoutNetclient{
 		StatusCode: 500,
 	},
 }
+
+// Fetcher serves its responses like FakeFetcherWithoutNetclient, with the behaviours set by its options
+type Fetcher struct {
+	responses FakeFetcherWithoutNetclient
+	fallback  func(url string) (*internal.HTTPResponse, error)
+	chunkSize int
+	// the urls starting with delayPrefix are answered after delay
+	delayPrefix string
+	delay       time.Duration
+	// cancel is called once the number of requests reaches cancelAfter
+	cancelAfter int
+	cancel      context.CancelFunc
+	mux         sync.Mutex
+	requests    int
+	fetched     map[string]int
+	read        map[string]int
+}
+
+// FetcherOption sets a behaviour of a Fetcher
+type FetcherOption func(f *Fetcher)
+
+// NewFetcher returns a fetcher serving responses, the other urls failing unless a fallback is set
+func NewFetcher(responses FakeFetcherWithoutNetclient, options ...FetcherOption) *Fetcher {
+	f := &Fetcher{responses: responses, fetched: make(map[string]int), read: make(map[string]int)}
+	for _, option := range options {
+		option(f)
+	}
+	return f
+}
+
+// WithFallback answers the urls without response
+func WithFallback(fallback func(url string) (*internal.HTTPResponse, error)) FetcherOption {
+	return func(f *Fetcher) {
+		f.fallback = fallback
+	}
+}
+
+// WithStatusCode answers the urls without response with an empty response of this status code
+func WithStatusCode(code int) FetcherOption {
+	return WithFallback(func(url string) (*internal.HTTPResponse, error) {
+		return &internal.HTTPResponse{StatusCode: code}, nil
+	})
+}
+
+// WithChunks serves the bodies in chunks of size bytes, stopping once the done function of FetchUntil returns true
+func WithChunks(size int) FetcherOption {
+	return func(f *Fetcher) {
+		f.chunkSize = size
+	}
+}
+
+// WithDelay answers the urls starting with prefix after delay, giving up when the context of the request is done
+func WithDelay(prefix string, delay time.Duration) FetcherOption {
+	return func(f *Fetcher) {
+		f.delayPrefix, f.delay = prefix, delay
+	}
+}
+
+// WithCancel calls cancel once the fetcher has received after requests
+func WithCancel(after int, cancel context.CancelFunc) FetcherOption {
+	return func(f *Fetcher) {
+		f.cancelAfter, f.cancel = after, cancel
+	}
+}
+
+func (f *Fetcher) Fetch(url string) (*internal.HTTPResponse, error) {
+	return f.FetchUntil(context.Background(), url, nil)
+}
+
+func (f *Fetcher) FetchUntil(ctx context.Context, url string, done func(resp *internal.HTTPResponse) bool) (*internal.HTTPResponse, error) {
+	f.mux.Lock()
+	f.requests++
+	f.fetched[url]++
+	if f.cancel != nil && f.requests == f.cancelAfter {
+		f.cancel()
+	}
+	f.mux.Unlock()
+	if f.delay > 0 && strings.HasPrefix(url, f.delayPrefix) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.delay):
+		}
+	}
+	f.mux.Lock()
+	resp, ok := f.responses[url]
+	f.mux.Unlock()
+	if !ok {
+		if f.fallback == nil {
+			return nil, fmt.Errorf("could not fetch : %s", url)
+		}
+		var err error
+		if resp, err = f.fallback(url); err != nil {
+			return nil, err
+		}
+	}
+	served := *resp
+	read := len(served.Body)
+	if f.chunkSize > 0 {
+		read = f.stream(&served, done)
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.read[url] = read
+	return &served, nil
+}
+
+// stream reads the body chunk by chunk until done returns true, returning the number of bytes read
+func (f *Fetcher) stream(resp *internal.HTTPResponse, done func(resp *internal.HTTPResponse) bool) int {
+	body := resp.Body
+	read := 0
+	for read < len(body) {
+		read += f.chunkSize
+		if read > len(body) {
+			read = len(body)
+		}
+		resp.Body = body[:read]
+		if read < len(body) && done != nil && done(resp) {
+			resp.Truncated = true
+			break
+		}
+	}
+	return read
+}
+
+// SetResponses replaces the responses served
+func (f *Fetcher) SetResponses(responses FakeFetcherWithoutNetclient) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.responses = responses
+}
+
+// Requests returns the number of requests received
+func (f *Fetcher) Requests() int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.requests
+}
+
+// Fetched returns the number of requests of url
+func (f *Fetcher) Fetched(url string) int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.fetched[url]
+}
+
+// Read returns the number of bytes of the body of url read by the last request
+func (f *Fetcher) Read(url string) int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.read[url]
+}
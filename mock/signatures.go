@@ -4,72 +4,97 @@ import (
 	"gochopchop/core"
 )
 
-func createInt32(x int32) *int32 {
-	return &x
-}
-
 // Checks
 
 var FakeCheckStatusCode200 = &core.Check{
+	ID:          "status-code-200",
+	Tags:        []string{"exposure"},
 	Name:        "StatusCode200",
 	Severity:    "Medium",
 	Remediation: "uninstall",
-	StatusCode:  createInt32(200),
+	Matchers: core.Matchers{
+		StatusCode: core.NewStatusCodes(200),
+	},
 }
 
 var FakeCheckStatusCode500 = &core.Check{
+	ID:          "status-code-500",
 	Name:        "StatusCode500",
 	Severity:    "High",
 	Remediation: "uninstall",
-	StatusCode:  createInt32(500),
+	Matchers: core.Matchers{
+		StatusCode: core.NewStatusCodes(500),
+	},
 }
 
 var FakeCheckNoHeaders = &core.Check{
+	ID:          "no-headers",
 	Name:        "NoHeaders",
 	Severity:    "Low",
 	Remediation: "uninstall",
-	NoHeaders:   []string{"NoHeader:ok"},
+	Matchers: core.Matchers{
+		NoHeaders: []core.HeaderMatcher{{Name: "NoHeader", Value: "ok"}},
+	},
 }
 
 var FakeCheckNoHeadersKeyOnly = &core.Check{
+	ID:          "no-headers-key-only",
 	Name:        "NoHeaders",
 	Severity:    "Informational",
 	Remediation: "uninstall",
-	NoHeaders:   []string{"NoHeader2"},
+	Matchers: core.Matchers{
+		NoHeaders: []core.HeaderMatcher{{Name: "NoHeader2"}},
+	},
 }
 
 var FakeCheckHeaders = &core.Check{
+	ID:          "headers",
+	Tags:        []string{"cms", "noisy"},
 	Name:        "Headers",
 	Severity:    "High",
 	Remediation: "uninstall",
-	Headers:     []string{"Header:ok"},
+	Matchers: core.Matchers{
+		Headers: []core.HeaderMatcher{{Name: "Header", Value: "ok"}},
+	},
 }
 var FakeCheckHeaders2 = &core.Check{
+	ID:          "headers-2",
 	Name:        "Headers",
 	Severity:    "Medium",
 	Remediation: "uninstall",
-	Headers:     []string{"Header2:ok"},
+	Matchers: core.Matchers{
+		Headers: []core.HeaderMatcher{{Name: "Header2", Value: "ok"}},
+	},
 }
 
 var FakeCheckMatchOne = &core.Check{
-	Name:         "MustMatchOne",
-	Severity:     "Low",
-	Remediation:  "uninstall",
-	MustMatchOne: []string{"MATCHONE", "MATCHTWO"},
+	ID:          "must-match-one",
+	Name:        "MustMatchOne",
+	Severity:    "Low",
+	Remediation: "uninstall",
+	Matchers: core.Matchers{
+		MustMatchOne: []string{"MATCHONE", "MATCHTWO"},
+	},
 }
 
 var FakeCheckMatchAll = &core.Check{
-	Name:         "MustMatchAll",
-	Severity:     "Informational",
-	Remediation:  "uninstall",
-	MustMatchAll: []string{"MATCHONE", "MATCHTWO"},
+	ID:          "must-match-all",
+	Name:        "MustMatchAll",
+	Severity:    "Informational",
+	Remediation: "uninstall",
+	Matchers: core.Matchers{
+		MustMatchAll: []string{"MATCHONE", "MATCHTWO"},
+	},
 }
 
 var FakeCheckNotMatch = &core.Check{
-	Name:         "MustNotMatch",
-	Severity:     "High",
-	Remediation:  "uninstall",
-	MustNotMatch: []string{"NOTMATCH"},
+	ID:          "must-not-match",
+	Name:        "MustNotMatch",
+	Severity:    "High",
+	Remediation: "uninstall",
+	Matchers: core.Matchers{
+		MustNotMatch: []string{"NOTMATCH"},
+	},
 }
 
 // Plugins
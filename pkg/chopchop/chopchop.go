@@ -0,0 +1,56 @@
+// Package chopchop is the public API of ChopChop, to embed its scanner in other Go programs.
+//
+// Signatures are loaded with LoadSignatures or ParseSignatures, then scanned for with a Scanner:
+//
+//	signatures, err := chopchop.LoadSignatures("chopchop.yml")
+//	scanner, err := chopchop.NewScanner(signatures, chopchop.WithThreads(10), chopchop.WithTimeout(5*time.Second))
+//	results, err := scanner.Scan(ctx, []string{"https://example.com"})
+//	err = chopchop.Export(os.Stdout, chopchop.FormatJSON, results)
+package chopchop
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"io/ioutil"
+)
+
+// Result is a finding of a scan: a check matched on the URL of an endpoint
+type Result = core.Output
+
+// Signatures are the plugins a scanner runs, a plugin being the checks run on the response of its endpoints
+type Signatures = core.Signatures
+
+// Plugin requests its endpoints and runs its checks on the responses
+type Plugin = core.Plugin
+
+// Check is a vulnerability or a misconfiguration matched on a response
+type Check = core.Check
+
+// Filter selects the checks of signatures to run
+type Filter = core.Filter
+
+// Response is an HTTP response checks are run on
+type Response = internal.HTTPResponse
+
+// Fetcher fetches the responses of a scan, see WithFetchers
+type Fetcher = core.IFetcher
+
+// NotRun is a plugin skipped on a URL because the time budget of its target ran out
+type NotRun = core.NotRun
+
+// NotRunError is returned by Scan along with the results when the time budget of a target ran out
+type NotRunError = core.NotRunError
+
+// LoadSignatures reads and validates a YAML signature file
+func LoadSignatures(filename string) (*Signatures, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignatures(data)
+}
+
+// ParseSignatures reads and validates YAML signatures
+func ParseSignatures(data []byte) (*Signatures, error) {
+	return core.ParseSignatures(data)
+}
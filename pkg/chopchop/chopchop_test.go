@@ -0,0 +1,140 @@
+package chopchop_test
+
+import (
+	"bytes"
+	"context"
+	"gochopchop/core"
+	"gochopchop/pkg/chopchop"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const signatures = `
+plugins:
+- endpoint: /.git/config
+  checks:
+  - name: Git exposed
+    description: The git repository is exposed
+    remediation: Remove the .git folder
+    severity: High
+    status_code: 200
+    match: ["[core]"]
+`
+
+func newGitServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.git/config" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("[core]\n\trepositoryformatversion = 0\n"))
+	}))
+}
+
+func TestScan(t *testing.T) {
+	server := newGitServer()
+	defer server.Close()
+
+	loaded, err := chopchop.ParseSignatures([]byte(signatures))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := chopchop.NewScanner(loaded, chopchop.WithThreads(2), chopchop.WithoutHeaderAudit())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := scanner.Scan(context.Background(), []string{server.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].ID != "git-exposed" || results[0].URL != server.URL+"/.git/config" {
+			t.Fatalf("scan %d: expected the git exposure only, got: %v", i+1, results)
+		}
+	}
+}
+
+func TestScanHeaderAuditByDefault(t *testing.T) {
+	server := newGitServer()
+	defer server.Close()
+
+	loaded, err := chopchop.ParseSignatures([]byte(signatures))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner, err := chopchop.NewScanner(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := scanner.Scan(context.Background(), []string{server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.ID == "missing-x-content-type-options" {
+			return
+		}
+	}
+	t.Errorf("expected the missing X-Content-Type-Options header to be reported, got: %v", results)
+}
+
+func TestNewScannerInvalidOptions(t *testing.T) {
+	loaded, err := chopchop.ParseSignatures([]byte(signatures))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = map[string]chopchop.Option{
+		"no thread":           chopchop.WithThreads(0),
+		"invalid soft-404":    chopchop.WithSoft404("hide"),
+		"unknown audit rule":  chopchop.WithHeaderAudit(map[string]string{"unknown": "High"}),
+		"invalid audit level": chopchop.WithHeaderAudit(map[string]string{"missing-hsts": "Huge"}),
+	}
+
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := chopchop.NewScanner(loaded, opt); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewScannerInvalidSignatures(t *testing.T) {
+	check := &chopchop.Check{ID: "server", Name: "Server", Description: "d", Remediation: "r", Severity: "Low"}
+	check.Headers = []core.HeaderMatcher{{Name: "Server", Regex: "("}}
+	signatures := &chopchop.Signatures{Plugins: []*chopchop.Plugin{{Endpoint: "/", Checks: []*chopchop.Check{check}}}}
+	if _, err := chopchop.NewScanner(signatures); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestExport(t *testing.T) {
+	results := []chopchop.Result{{URL: "http://site/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", ID: "git-exposed"}}
+
+	var json bytes.Buffer
+	if err := chopchop.Export(&json, chopchop.FormatJSON, results); err != nil {
+		t.Fatal(err)
+	}
+	read, err := chopchop.ReadJSON(&json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].ID != "git-exposed" {
+		t.Errorf("expected: %v, got: %v", results, read)
+	}
+
+	var csv bytes.Buffer
+	if err := chopchop.Export(&csv, chopchop.FormatCSV, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "http://site/.git/config,") {
+		t.Errorf("unexpected CSV export: %s", csv.String())
+	}
+
+	if err := chopchop.Export(&csv, "xml", results); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,58 @@
+package chopchop
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/export"
+	"io"
+	"time"
+)
+
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Result statuses set by CompareBaseline
+const (
+	StatusNew      = core.StatusNew
+	StatusPresent  = core.StatusPresent
+	StatusResolved = core.StatusResolved
+)
+
+// Suppressions are the results accepted as risks, see ApplySuppressions
+type Suppressions = core.Suppressions
+
+// Suppression accepts the results matching its id, name and URL pattern
+type Suppression = core.Suppression
+
+// Export writes the results to w in the format, FormatJSON or FormatCSV
+func Export(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatJSON:
+		return export.WriteJSON(w, results)
+	case FormatCSV:
+		return export.WriteCSV(w, results)
+	}
+	return fmt.Errorf("Invalid export format : %s. Please use : %s, %s", format, FormatJSON, FormatCSV)
+}
+
+// ReadJSON reads the results of a JSON export
+func ReadJSON(r io.Reader) ([]Result, error) {
+	return export.ReadJSON(r)
+}
+
+// CompareBaseline sets the status of the results compared to the ones of a previous scan,
+// the results of the baseline no longer found being added as resolved
+func CompareBaseline(results []Result, baseline []Result) []Result {
+	return core.CompareBaseline(results, baseline)
+}
+
+// ApplySuppressions marks the results accepted as risks by the suppressions, which are validated first
+func ApplySuppressions(results []Result, suppressions *Suppressions) ([]Result, error) {
+	if err := suppressions.Validate(); err != nil {
+		return nil, err
+	}
+	return suppressions.Apply(results, time.Now()), nil
+}
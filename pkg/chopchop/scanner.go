@@ -0,0 +1,168 @@
+package chopchop
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/httpget"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Soft-404 modes, what happens to the results on the catch-all page of a target
+const (
+	Soft404Off       = core.Soft404Off
+	Soft404Suppress  = core.Soft404Suppress
+	Soft404Downgrade = core.Soft404Downgrade
+)
+
+// Scanner runs the checks of signatures on URLs.
+// A scanner holds no result between scans and can run concurrent scans, each Scan returning only its own results.
+// Scans running different checks use scanners of the signatures returned by Signatures.Filtered.
+type Scanner struct {
+	scanner *core.Scanner
+}
+
+type options struct {
+	threads               int
+	insecure              bool
+	timeout               time.Duration
+	maxBodySize           int64
+	proxy                 *url.URL
+	headers               http.Header
+	fetcher               Fetcher
+	noRedirectFetcher     Fetcher
+	headerAudit           bool
+	headerAuditSeverities map[string]string
+	soft404               string
+	targetBudget          time.Duration
+}
+
+// Option sets up a Scanner
+type Option func(o *options)
+
+// WithThreads sets the number of URLs fetched at once, 1 by default
+func WithThreads(threads int) Option {
+	return func(o *options) {
+		o.threads = threads
+	}
+}
+
+// WithInsecure skips the verification of TLS certificates
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+	}
+}
+
+// WithTimeout sets the timeout of the HTTP requests, rounded up to the second, 10 seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxBodySize sets the number of bytes read at most from a response body, 10 MiB by default and 0 for no limit
+func WithMaxBodySize(size int64) Option {
+	return func(o *options) {
+		o.maxBodySize = size
+	}
+}
+
+// WithProxy sends the requests through a http, https or socks5 proxy
+func WithProxy(proxy *url.URL) Option {
+	return func(o *options) {
+		o.proxy = proxy
+	}
+}
+
+// WithHeaders adds the headers to every request
+func WithHeaders(headers http.Header) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithFetchers replaces the HTTP clients of the scanner, the plugins following redirects using fetcher
+// and the others noRedirectFetcher. The HTTP options are then ignored.
+func WithFetchers(fetcher Fetcher, noRedirectFetcher Fetcher) Option {
+	return func(o *options) {
+		o.fetcher = fetcher
+		o.noRedirectFetcher = noRedirectFetcher
+	}
+}
+
+// WithHeaderAudit overrides the severity of the header audit rules by id, the audit being run by default
+func WithHeaderAudit(severities map[string]string) Option {
+	return func(o *options) {
+		o.headerAudit = true
+		o.headerAuditSeverities = severities
+	}
+}
+
+// WithoutHeaderAudit doesn't report missing or weak security headers
+func WithoutHeaderAudit() Option {
+	return func(o *options) {
+		o.headerAudit = false
+	}
+}
+
+// WithSoft404 sets the soft-404 mode, Soft404Off by default
+func WithSoft404(mode string) Option {
+	return func(o *options) {
+		o.soft404 = mode
+	}
+}
+
+// WithTargetBudget sets the time given to each target, its remaining plugins being skipped once over
+func WithTargetBudget(budget time.Duration) Option {
+	return func(o *options) {
+		o.targetBudget = budget
+	}
+}
+
+// NewScanner validates the signatures and returns a scanner of them, the signatures must not be modified while it is in use
+func NewScanner(signatures *Signatures, opts ...Option) (*Scanner, error) {
+	o := &options{threads: 1, timeout: 10 * time.Second, maxBodySize: 10 * 1024 * 1024, headerAudit: true, soft404: Soft404Off}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.threads < 1 {
+		return nil, fmt.Errorf("Invalid number of threads : %d. Please use at least 1", o.threads)
+	}
+	if !core.ValidSoft404Mode(o.soft404) {
+		return nil, fmt.Errorf("Invalid soft-404 mode : %s. Please use : %s", o.soft404, core.Soft404ModesAsString())
+	}
+	// signatures built in code have their regexes compiled here
+	if err := signatures.Validate(); err != nil {
+		return nil, err
+	}
+
+	fetcher, noRedirectFetcher := o.fetcher, o.noRedirectFetcher
+	if fetcher == nil || noRedirectFetcher == nil {
+		timeout := int((o.timeout + time.Second - 1) / time.Second)
+		fetcher = httpget.NewFetcher(o.insecure, timeout, o.maxBodySize, o.proxy, o.headers)
+		noRedirectFetcher = httpget.NewNoRedirectFetcher(o.insecure, timeout, o.maxBodySize, o.proxy, o.headers)
+	}
+
+	scanner := core.NewScanner(fetcher, noRedirectFetcher, signatures, o.threads)
+	scanner.Soft404 = o.soft404
+	scanner.TargetBudget = o.targetBudget
+	scanner.HeaderAudit = nil
+	if o.headerAudit {
+		audit, err := core.NewHeaderAudit(o.headerAuditSeverities)
+		if err != nil {
+			return nil, err
+		}
+		scanner.HeaderAudit = audit
+	}
+	return &Scanner{scanner: scanner}, nil
+}
+
+// Scan runs the checks on the urls, each url being the base the endpoints of the plugins are appended to.
+// When ctx is done the scan stops and returns the results gathered so far along with ctx.Err(),
+// and when the time budget of a target ran out a *NotRunError listing the plugins skipped.
+func (s *Scanner) Scan(ctx context.Context, urls []string) ([]Result, error) {
+	return s.scanner.Scan(ctx, urls)
+}